@@ -1,19 +1,51 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/conf"
+	"github.com/MrBista/The-Crawler/internal/canonical"
+	"github.com/MrBista/The-Crawler/internal/dedup"
+	"github.com/MrBista/The-Crawler/internal/handler"
 	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/webhook"
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 )
 
 func main() {
 	fmt.Println("Hello World")
 
+	envConv, err := conf.LoadConfig()
+
+	if err != nil {
+		log.Panicf("failed to load config %v", err)
+	}
+
+	dbConnect, err := conf.Connect(envConv.DBConfig)
+
+	if err != nil {
+		log.Panicf("failed to connect database %v", err)
+	}
+
+	crawlRepository := repository.NewCrawlRepositoryImpl(dbConnect)
+	linkRepository := repository.NewLinkRepositoryImpl(dbConnect)
+	linkCheckRepository := repository.NewLinkCheckRepositoryImpl(dbConnect)
+	jobRepository := repository.NewJobRepositoryImpl(dbConnect)
+	sessionRepository := repository.NewSessionRepositoryImpl(dbConnect)
+	deadLetterRepository := repository.NewDeadLetterRepositoryImpl(dbConnect)
+	pageVersionRepository := repository.NewPageVersionRepositoryImpl(dbConnect)
+	watchRepository := repository.NewWatchRepositoryImpl(dbConnect)
+	scheduleRepository := repository.NewScheduleRepositoryImpl(dbConnect)
+	visitedSet := dedup.NewPostgresVisitedSet(dbConnect)
+	canonicalizer := canonical.New(canonical.Options{
+		KeepTrackingParams: envConv.Canonical.KeepTrackingParams,
+		TrackingParams:     envConv.Canonical.TrackingParams,
+		TrailingSlash:      envConv.Canonical.TrailingSlash,
+	})
+
 	app := fiber.New()
 
 	brokers := []string{"localhost:9092"}
@@ -27,58 +59,46 @@ func main() {
 
 	defer producer.Close()
 
-	app.Post("/api/v1/crawl", func(c *fiber.Ctx) error {
-		var reqBody models.CrawlJob
-
-		if err := c.BodyParser(&reqBody); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"data":    nil,
-				"message": "failed to parse body",
-			})
-		}
-
-		if reqBody.Url == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"data":    nil,
-				"message": "url body is required",
-			})
-		}
-
-		jobId := uuid.New().String()
-		job := models.CrawlJob{
-			ID:        jobId,
-			Url:       reqBody.Url,
-			Depth:     reqBody.Depth,
-			Selectors: reqBody.Selectors,
-		}
-
-		jobMarshal, err := json.Marshal(job)
-
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"data":    nil,
-				"message": "url body is required",
-			})
-		}
-		partion, offset, err := producer.PublishMessage(topic, jobId, string(jobMarshal))
-
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"data":    nil,
-				"message": "url body is required",
-			})
-		}
-
-		log.Printf("Message stored in topic (%s) with partion = %v and offset = %v", topic, partion, offset)
-
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"data": fiber.Map{
-				"job_id":  jobId,
-				"status":  "pending",
-				"Message": "message stored in brokers",
-			},
-		})
-	})
+	crawlApiHandler := handler.NewCrawlApiHandler(crawlRepository, linkRepository, jobRepository, sessionRepository, visitedSet, canonicalizer, producer, topic)
+	sessionApiHandler := handler.NewSessionApiHandler(sessionRepository, linkRepository, linkCheckRepository)
+	pageApiHandler := handler.NewPageApiHandler(crawlRepository, pageVersionRepository)
+	watchApiHandler := handler.NewWatchApiHandler(watchRepository, canonicalizer)
+	scheduleApiHandler := handler.NewScheduleApiHandler(scheduleRepository, canonicalizer)
+	deadLetterApiHandler := handler.NewDeadLetterApiHandler(deadLetterRepository, jobRepository, sessionRepository, producer, topic)
+
+	app.Post("/api/v1/crawl", crawlApiHandler.SubmitCrawl)
+	app.Get("/api/v1/crawl/:id", crawlApiHandler.GetCrawl)
+	app.Get("/api/v1/crawl/:id/pages", crawlApiHandler.GetCrawlPages)
+	app.Get("/api/v1/crawl/:id/outlinks", crawlApiHandler.GetOutlinks)
+	app.Get("/api/v1/crawl/:id/inlinks", crawlApiHandler.GetInlinks)
+	app.Post("/api/v1/crawl/:id/cancel", crawlApiHandler.CancelCrawl)
+	app.Post("/api/v1/crawl/:id/pause", crawlApiHandler.PauseCrawl)
+	app.Post("/api/v1/crawl/:id/resume", crawlApiHandler.ResumeCrawl)
+	app.Get("/api/v1/sessions/:id", sessionApiHandler.GetSession)
+	app.Get("/api/v1/sessions/:id/broken-links", sessionApiHandler.GetBrokenLinks)
+	app.Get("/api/v1/pages/:id/versions", pageApiHandler.GetVersions)
+	app.Get("/api/v1/pages/:id/diff", pageApiHandler.GetDiff)
+	app.Post("/api/v1/watches", watchApiHandler.CreateWatch)
+	app.Get("/api/v1/watches", watchApiHandler.ListWatches)
+	app.Get("/api/v1/watches/:id", watchApiHandler.GetWatch)
+	app.Delete("/api/v1/watches/:id", watchApiHandler.DeleteWatch)
+	app.Get("/api/v1/watches/:id/deliveries", watchApiHandler.GetDeliveries)
+	app.Post("/api/v1/schedules", scheduleApiHandler.CreateSchedule)
+	app.Get("/api/v1/schedules", scheduleApiHandler.ListSchedules)
+	app.Get("/api/v1/schedules/:id", scheduleApiHandler.GetSchedule)
+	app.Delete("/api/v1/schedules/:id", scheduleApiHandler.DeleteSchedule)
+	app.Post("/api/v1/schedules/:id/pause", scheduleApiHandler.PauseSchedule)
+	app.Post("/api/v1/schedules/:id/resume", scheduleApiHandler.ResumeSchedule)
+	app.Get("/api/v1/dead-letters", deadLetterApiHandler.ListDeadLetters)
+	app.Post("/api/v1/dead-letters/:id/replay", deadLetterApiHandler.ReplayDeadLetter)
+
+	// Watch dan schedule dijalankan dari proses API, aman dijalankan di beberapa replica
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchRunner := handler.NewWatchRunner(watchRepository, jobRepository, sessionRepository, visitedSet, webhook.NewSender(envConv.Crawler.UserAgent), producer, topic)
+	go watchRunner.Run(ctx)
+	scheduleRunner := handler.NewScheduleRunner(scheduleRepository, jobRepository, sessionRepository, visitedSet, producer, topic)
+	go scheduleRunner.Run(ctx)
 
 	log.Printf("Successfully listen to port 3000")
 
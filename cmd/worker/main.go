@@ -6,14 +6,20 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 
 	"github.com/MrBista/The-Crawler/conf"
+	"github.com/MrBista/The-Crawler/internal/canonical"
+	"github.com/MrBista/The-Crawler/internal/dedup"
 	"github.com/MrBista/The-Crawler/internal/handler"
+	"github.com/MrBista/The-Crawler/internal/politeness"
 	"github.com/MrBista/The-Crawler/internal/queue"
 	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/robots"
 	"github.com/MrBista/The-Crawler/internal/storage"
+	"github.com/MrBista/The-Crawler/internal/webhook"
 )
 
 func main() {
@@ -28,6 +34,29 @@ func main() {
 	dbConnect, _ := conf.Connect(envConv.DBConfig)
 
 	crawlRepository := repository.NewCrawlRepositoryImpl(dbConnect)
+	linkRepository := repository.NewLinkRepositoryImpl(dbConnect)
+	linkCheckRepository := repository.NewLinkCheckRepositoryImpl(dbConnect)
+	pageVersionRepository := repository.NewPageVersionRepositoryImpl(dbConnect)
+	watchRepository := repository.NewWatchRepositoryImpl(dbConnect)
+	jobRepository := repository.NewJobRepositoryImpl(dbConnect)
+	sessionRepository := repository.NewSessionRepositoryImpl(dbConnect)
+	deadLetterRepository := repository.NewDeadLetterRepositoryImpl(dbConnect)
+	visitedSet := dedup.NewPostgresVisitedSet(dbConnect)
+	canonicalizer := canonical.New(canonical.Options{
+		KeepTrackingParams: envConv.Canonical.KeepTrackingParams,
+		TrackingParams:     envConv.Canonical.TrackingParams,
+		TrailingSlash:      envConv.Canonical.TrailingSlash,
+	})
+
+	robotsCache := robots.NewCache(envConv.Crawler.RobotsAgent, envConv.Crawler.UserAgent, envConv.Crawler.RobotsCacheTTL)
+
+	var limiter politeness.Limiter
+	policies := buildPolitenessPolicies(envConv.Politeness)
+	if envConv.Politeness.Mode == "local" {
+		limiter = politeness.NewLocalLimiter(policies)
+	} else {
+		limiter = politeness.NewPostgresLimiter(dbConnect, policies)
+	}
 
 	fileStore := storage.NewLocalStorage("./storage/crawl_data/files")
 
@@ -42,9 +71,21 @@ func main() {
 
 	defer producer.Close()
 
-	crawlHandler := handler.NewCrawlHandler(crawlRepository, fileStore, producer, topic)
+	crawlHandler := handler.NewCrawlHandler(crawlRepository, linkRepository, linkCheckRepository, pageVersionRepository, watchRepository, jobRepository, sessionRepository, visitedSet, canonicalizer, robotsCache, limiter, envConv.Crawler.UserAgent, fileStore, webhook.NewSender(envConv.Crawler.UserAgent), producer, topic)
+
+	retryPolicy := handler.RetryPolicy{
+		MaxAttempts: envConv.Retry.MaxAttempts,
+		BaseDelay:   envConv.Retry.BaseDelay,
+		MaxDelay:    envConv.Retry.MaxDelay,
+	}
+	if retryPolicy.MaxAttempts < 1 {
+		log.Panicf("retry.max_attempts must be at least 1, got %d", retryPolicy.MaxAttempts)
+	}
+
+	consumerCrawlHandler, err := handler.NewConsumerCrawlerHandler(crawlHandler, producer, deadLetterRepository, retryPolicy, topic)
 
-	consumerCrawlHandler, err := handler.NewConsumerCrawlerHandler(crawlHandler)
+	// Worker juga membaca topic retry, pesan di sana menunggu NotBefore-nya
+	topics := append([]string{topic}, queue.RetryTopics(topic, retryPolicy.MaxAttempts)...)
 
 	group, err := queue.NewConsumerGroup(brokers, groupConsumerId, topic)
 	if err != nil {
@@ -66,7 +107,7 @@ func main() {
 		defer wg.Done()
 		for {
 
-			if err := group.ConsumerGroup.Consume(ctx, []string{topic}, consumerCrawlHandler); err != nil {
+			if err := group.ConsumerGroup.Consume(ctx, topics, consumerCrawlHandler); err != nil {
 				log.Printf("Error from consumer: %v", err)
 			}
 
@@ -83,3 +124,24 @@ func main() {
 	cancel()
 	wg.Wait()
 }
+
+func buildPolitenessPolicies(cfg conf.PolitenessConfig) politeness.Policies {
+	policies := politeness.Policies{
+		Default: politeness.Policy{
+			RequestsPerSecond: cfg.RequestsPerSecond,
+			MaxConcurrent:     cfg.MaxConcurrent,
+			MinDelay:          cfg.MinDelay,
+		},
+		Overrides: make(map[string]politeness.Policy),
+	}
+
+	for _, domain := range cfg.Domains {
+		policies.Overrides[strings.ToLower(domain.Domain)] = politeness.Policy{
+			RequestsPerSecond: domain.RequestsPerSecond,
+			MaxConcurrent:     domain.MaxConcurrent,
+			MinDelay:          domain.MinDelay,
+		}
+	}
+
+	return policies
+}
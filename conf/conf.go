@@ -3,12 +3,17 @@ package conf
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBConfig DBConfig `mapstructure:"db"`
+	DBConfig   DBConfig         `mapstructure:"db"`
+	Canonical  CanonicalConfig  `mapstructure:"canonical"`
+	Crawler    CrawlerConfig    `mapstructure:"crawler"`
+	Politeness PolitenessConfig `mapstructure:"politeness"`
+	Retry      RetryConfig      `mapstructure:"retry"`
 }
 
 type DBConfig struct {
@@ -20,6 +25,43 @@ type DBConfig struct {
 	SSLMode  string `mapstructure:"ssl_mode"`
 }
 
+type CanonicalConfig struct {
+	KeepTrackingParams bool     `mapstructure:"keep_tracking_params"`
+	TrackingParams     []string `mapstructure:"tracking_params"`
+	TrailingSlash      string   `mapstructure:"trailing_slash"` // keep, strip atau add
+}
+
+type CrawlerConfig struct {
+	UserAgent      string        `mapstructure:"user_agent"`
+	RobotsAgent    string        `mapstructure:"robots_agent"` // product token yang dicocokkan di robots.txt
+	RobotsCacheTTL time.Duration `mapstructure:"robots_cache_ttl"`
+}
+
+type PolitenessConfig struct {
+	Mode              string                   `mapstructure:"mode"` // postgres (antar worker) atau local
+	RequestsPerSecond float64                  `mapstructure:"requests_per_second"`
+	MaxConcurrent     int                      `mapstructure:"max_concurrent"`
+	MinDelay          time.Duration            `mapstructure:"min_delay"`
+	Domains           []DomainPolitenessConfig `mapstructure:"domains"`
+}
+
+// DomainPolitenessConfig overrides the default limits for a domain and its
+// subdomains. Zero fields keep the default.
+type DomainPolitenessConfig struct {
+	Domain            string        `mapstructure:"domain"`
+	RequestsPerSecond float64       `mapstructure:"requests_per_second"`
+	MaxConcurrent     int           `mapstructure:"max_concurrent"`
+	MinDelay          time.Duration `mapstructure:"min_delay"`
+}
+
+// RetryConfig controls how often a failed job is retried before it goes to
+// the dead-letter topic.
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"` // termasuk percobaan pertama
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+}
+
 func LoadConfig() (*Config, error) {
 	v := viper.New()
 	v.SetConfigName("config")
@@ -28,7 +70,15 @@ func LoadConfig() (*Config, error) {
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	// v.set
+	v.SetDefault("crawler.user_agent", "Mozilla/5.0 (compatible; TheCrawler/1.0)")
+	v.SetDefault("crawler.robots_agent", "TheCrawler")
+	v.SetDefault("crawler.robots_cache_ttl", "24h")
+	v.SetDefault("politeness.mode", "postgres")
+	v.SetDefault("politeness.requests_per_second", 1)
+	v.SetDefault("politeness.max_concurrent", 2)
+	v.SetDefault("retry.max_attempts", 5)
+	v.SetDefault("retry.base_delay", "5s")
+	v.SetDefault("retry.max_delay", "5m")
 
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -35,8 +35,8 @@ func Connect(cfg DBConfig) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&models.CrawlPage{}); err != nil {
-		log.Printf("Failed to migrate CrawlPage: %v", err)
+	if err := db.AutoMigrate(&models.CrawlPage{}, &models.CrawlJobRecord{}, &models.CrawlSession{}, &models.SessionHostCount{}, &models.ParkedJob{}, &models.VisitedURL{}, &models.HostThrottle{}, &models.HostLease{}, &models.DeadLetter{}, &models.PageLink{}, &models.LinkCheck{}, &models.PageIdentity{}, &models.PageVersion{}, &models.Watch{}, &models.WebhookDelivery{}, &models.WebhookAttempt{}, &models.CrawlSchedule{}); err != nil {
+		log.Printf("Failed to migrate models: %v", err)
 		return nil, err
 	}
 	log.Println("Migration successful!")
@@ -2,6 +2,8 @@ module github.com/MrBista/The-Crawler
 
 go 1.25.1
 
+require golang.org/x/net v0.47.0
+
 require (
 	github.com/IBM/sarama v1.46.3 // indirect
 	github.com/PuerkitoBio/goquery v1.11.0 // indirect
@@ -49,7 +51,6 @@ require (
 	github.com/valyala/tcplisten v1.0.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/crypto v0.44.0 // indirect
-	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
@@ -0,0 +1,294 @@
+package canonical
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	TrailingSlashKeep  = "keep"
+	TrailingSlashStrip = "strip"
+	TrailingSlashAdd   = "add"
+)
+
+var ErrNotAbsolute = errors.New("url must be absolute with scheme and host")
+
+// DefaultTrackingParams are query parameters that never change page content.
+var DefaultTrackingParams = []string{
+	"gclid", "dclid", "fbclid", "msclkid", "yclid", "igshid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "ref_src",
+}
+
+// DefaultTrackingPrefixes drops whole families such as utm_source, utm_medium.
+var DefaultTrackingPrefixes = []string{"utm_"}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+type Options struct {
+	// KeepTrackingParams disables removal of tracking query parameters.
+	KeepTrackingParams bool
+	// TrackingParams are removed in addition to DefaultTrackingParams.
+	TrackingParams []string
+	// TrailingSlash is one of TrailingSlashKeep (default), TrailingSlashStrip
+	// or TrailingSlashAdd.
+	TrailingSlash string
+}
+
+type Canonicalizer struct {
+	opts     Options
+	tracking map[string]bool
+}
+
+func New(opts Options) *Canonicalizer {
+	tracking := make(map[string]bool)
+	for _, p := range DefaultTrackingParams {
+		tracking[p] = true
+	}
+	for _, p := range opts.TrackingParams {
+		tracking[strings.ToLower(p)] = true
+	}
+
+	return &Canonicalizer{
+		opts:     opts,
+		tracking: tracking,
+	}
+}
+
+// Resolve resolves href against base and canonicalizes the result.
+func (c *Canonicalizer) Resolve(base, href string) (string, error) {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", err
+	}
+	return c.canonicalize(baseUrl.ResolveReference(ref))
+}
+
+// Canonicalize returns the canonical form of an absolute URL so equivalent
+// spellings of the same resource compare equal.
+func (c *Canonicalizer) Canonicalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	return c.canonicalize(u)
+}
+
+func (c *Canonicalizer) canonicalize(u *url.URL) (string, error) {
+	if u.Scheme == "" || u.Host == "" {
+		return "", ErrNotAbsolute
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && port != defaultPorts[scheme] {
+		host = host + ":" + port
+	}
+
+	path := normalizePercentEncoding(u.EscapedPath())
+	path = removeDotSegments(path)
+	path = c.applyTrailingSlash(path)
+
+	var b strings.Builder
+	b.WriteString(scheme)
+	b.WriteString("://")
+	if u.User != nil {
+		b.WriteString(u.User.String())
+		b.WriteString("@")
+	}
+	b.WriteString(host)
+	b.WriteString(path)
+
+	if query := c.normalizeQuery(u.RawQuery); query != "" {
+		b.WriteString("?")
+		b.WriteString(query)
+	}
+
+	return b.String(), nil
+}
+
+func (c *Canonicalizer) applyTrailingSlash(path string) string {
+	if path == "/" {
+		return path
+	}
+
+	switch c.opts.TrailingSlash {
+	case TrailingSlashStrip:
+		return strings.TrimRight(path, "/")
+	case TrailingSlashAdd:
+		if strings.HasSuffix(path, "/") {
+			return path
+		}
+		// Jangan tambah slash untuk file seperti /style.css
+		last := path[strings.LastIndex(path, "/")+1:]
+		if strings.Contains(last, ".") {
+			return path
+		}
+		return path + "/"
+	default:
+		return path
+	}
+}
+
+func (c *Canonicalizer) isTracking(key string) bool {
+	if c.opts.KeepTrackingParams {
+		return false
+	}
+	key = strings.ToLower(key)
+	if c.tracking[key] {
+		return true
+	}
+	for _, prefix := range DefaultTrackingPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type queryParam struct {
+	key   string
+	value string
+	raw   string
+}
+
+// normalizeQuery sorts parameters by key (stable, so repeated keys keep their
+// order) and drops tracking parameters.
+func (c *Canonicalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var params []queryParam
+	for _, part := range strings.Split(rawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		key = normalizePercentEncoding(key)
+		value = normalizePercentEncoding(value)
+		decodedKey, err := url.QueryUnescape(key)
+		if err != nil {
+			decodedKey = key
+		}
+		if c.isTracking(decodedKey) {
+			continue
+		}
+
+		raw := key
+		if strings.Contains(part, "=") {
+			raw = key + "=" + value
+		}
+		params = append(params, queryParam{key: decodedKey, value: value, raw: raw})
+	}
+
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].key < params[j].key
+	})
+
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = p.raw
+	}
+	return strings.Join(parts, "&")
+}
+
+func isUnreserved(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9') ||
+		b == '-' || b == '.' || b == '_' || b == '~'
+}
+
+func unhex(b byte) (byte, bool) {
+	switch {
+	case '0' <= b && b <= '9':
+		return b - '0', true
+	case 'a' <= b && b <= 'f':
+		return b - 'a' + 10, true
+	case 'A' <= b && b <= 'F':
+		return b - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// normalizePercentEncoding decodes escaped unreserved characters and
+// uppercases the hex digits of every escape that has to stay.
+func normalizePercentEncoding(s string) string {
+	const upperHex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			// Escape yang terpotong di akhir string
+			b.WriteString("%25")
+			continue
+		}
+
+		hi, ok1 := unhex(s[i+1])
+		lo, ok2 := unhex(s[i+2])
+		if !ok1 || !ok2 {
+			b.WriteString("%25")
+			continue
+		}
+
+		decoded := hi<<4 | lo
+		if isUnreserved(decoded) {
+			b.WriteByte(decoded)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(upperHex[decoded>>4])
+			b.WriteByte(upperHex[decoded&0x0f])
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// removeDotSegments implements RFC 3986 section 5.2.4.
+func removeDotSegments(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	out := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch seg {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, seg)
+		}
+	}
+
+	result := strings.Join(out, "/")
+	if !strings.HasPrefix(result, "/") {
+		result = "/" + result
+	}
+	return result
+}
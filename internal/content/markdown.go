@@ -0,0 +1,394 @@
+package content
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+var (
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+	markdownSpecial = regexp.MustCompile(`([\\` + "`" + `*_\[\]])`)
+)
+
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "figcaption": true, "figure": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true,
+	"ol": true, "p": true, "pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// ToMarkdown renders s as GitHub flavoured Markdown. Headings, lists, links,
+// images, code blocks and tables are kept, other markup is flattened to text.
+func ToMarkdown(s *goquery.Selection, base *url.URL) string {
+	w := &markdownWriter{base: base}
+	for _, node := range s.Nodes {
+		w.children(node)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(trimBlankLines(w.b.String()), "\n\n")) + "\n"
+}
+
+// trimBlankLines mengosongkan baris yang hanya berisi spasi supaya blankLines bisa merapikannya
+func trimBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ToText renders s as plain text with a blank line between blocks.
+func ToText(s *goquery.Selection) string {
+	var b strings.Builder
+	for _, node := range s.Nodes {
+		writeText(&b, node)
+	}
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = normalizeSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func writeText(b *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(node.Data)
+		return
+	case html.ElementNode:
+		if node.Data == "br" {
+			b.WriteString("\n")
+			return
+		}
+		if node.Data == "td" || node.Data == "th" {
+			b.WriteString(" ")
+		}
+	}
+
+	block := node.Type == html.ElementNode && blockTags[node.Data]
+	if block {
+		b.WriteString("\n\n")
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeText(b, child)
+	}
+	if block {
+		b.WriteString("\n\n")
+	}
+}
+
+type markdownWriter struct {
+	b    strings.Builder
+	base *url.URL
+	// listDepth dipakai untuk indentasi list bersarang
+	listDepth int
+}
+
+func (w *markdownWriter) children(node *html.Node) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		w.node(child)
+	}
+}
+
+func (w *markdownWriter) write(text string) {
+	w.b.WriteString(text)
+}
+
+// space menulis satu spasi kecuali output sudah diakhiri whitespace
+func (w *markdownWriter) space() {
+	out := w.b.String()
+	if out == "" || strings.ContainsAny(out[len(out)-1:], " \n") {
+		return
+	}
+	w.b.WriteString(" ")
+}
+
+func (w *markdownWriter) block(render func()) {
+	w.write("\n\n")
+	render()
+	w.write("\n\n")
+}
+
+func (w *markdownWriter) node(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		text := strings.Join(strings.Fields(node.Data), " ")
+		if startsWithSpace(node.Data) {
+			w.space()
+		}
+		if text == "" {
+			return
+		}
+		w.write(markdownSpecial.ReplaceAllString(text, `\$1`))
+		if endsWithSpace(node.Data) {
+			w.space()
+		}
+		return
+	case html.ElementNode:
+	default:
+		w.children(node)
+		return
+	}
+
+	switch node.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(node.Data[1] - '0')
+		w.block(func() {
+			w.write(strings.Repeat("#", level) + " " + inline(node, w.base))
+		})
+	case "p", "div", "section", "article", "main", "header", "figure", "address", "dl":
+		w.block(func() { w.children(node) })
+	case "dt":
+		w.block(func() { w.write("**" + inline(node, w.base) + "**") })
+	case "dd", "figcaption":
+		w.block(func() { w.children(node) })
+	case "br":
+		w.write("  \n")
+	case "hr":
+		w.block(func() { w.write("---") })
+	case "strong", "b":
+		w.wrapInline(node, "**")
+	case "em", "i":
+		w.wrapInline(node, "_")
+	case "del", "s", "strike":
+		w.wrapInline(node, "~~")
+	case "code", "kbd", "samp":
+		text := textContent(node)
+		fence := "`"
+		if strings.Contains(text, "`") {
+			fence = "``"
+		}
+		w.write(fence + text + fence)
+	case "pre":
+		w.block(func() {
+			text := strings.TrimRight(textContent(node), "\n")
+			fence := "```"
+			for strings.Contains(text, fence) {
+				fence += "`"
+			}
+			w.write(fence + codeLanguage(node) + "\n" + text + "\n" + fence)
+		})
+	case "a":
+		text := inline(node, w.base)
+		href := resolve(w.base, attr(node, "href"))
+		if href == "" || strings.HasPrefix(href, "javascript:") {
+			w.write(text)
+			return
+		}
+		if text == "" {
+			text = href
+		}
+		w.write("[" + text + "](" + escapeURL(href) + ")")
+	case "img":
+		src := resolve(w.base, attr(node, "src"))
+		if src == "" {
+			return
+		}
+		w.write("![" + markdownSpecial.ReplaceAllString(attr(node, "alt"), `\$1`) + "](" + escapeURL(src) + ")")
+	case "ul", "ol":
+		w.list(node)
+	case "blockquote":
+		w.block(func() {
+			quote := &markdownWriter{base: w.base}
+			quote.children(node)
+			body := strings.TrimSpace(blankLines.ReplaceAllString(quote.b.String(), "\n\n"))
+			lines := strings.Split(body, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+strings.TrimSpace(line), " ")
+			}
+			w.write(strings.Join(lines, "\n"))
+		})
+	case "table":
+		w.block(func() { w.table(node) })
+	default:
+		w.children(node)
+	}
+}
+
+func (w *markdownWriter) wrapInline(node *html.Node, marker string) {
+	text := inline(node, w.base)
+	if text == "" {
+		return
+	}
+	w.write(marker + text + marker)
+}
+
+func (w *markdownWriter) list(node *html.Node) {
+	ordered := node.Data == "ol"
+	indent := strings.Repeat("   ", w.listDepth)
+	if w.listDepth == 0 {
+		w.write("\n\n")
+	}
+
+	w.listDepth++
+	index := 1
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.Data != "li" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", index)
+		}
+		index++
+
+		// Isi li dirender terpisah supaya list bersarang bisa diindentasi
+		item := &markdownWriter{base: w.base, listDepth: w.listDepth}
+		item.children(child)
+		body := strings.TrimSpace(blankLines.ReplaceAllString(item.b.String(), "\n\n"))
+		body = strings.ReplaceAll(body, "\n\n", "\n")
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			if i > 0 && !strings.HasPrefix(line, indent+"   ") {
+				lines[i] = indent + "   " + strings.TrimSpace(line)
+			}
+		}
+		w.write("\n" + indent + marker + strings.Join(lines, "\n"))
+	}
+	w.listDepth--
+
+	if w.listDepth == 0 {
+		w.write("\n\n")
+	}
+}
+
+func (w *markdownWriter) table(node *html.Node) {
+	var rows [][]string
+	headerRow := -1
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.Data {
+			case "tr":
+				var cells []string
+				isHeader := true
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type != html.ElementNode || (cell.Data != "td" && cell.Data != "th") {
+						continue
+					}
+					if cell.Data == "td" {
+						isHeader = false
+					}
+					text := strings.ReplaceAll(inline(cell, w.base), "|", `\|`)
+					cells = append(cells, text)
+				}
+				if len(cells) == 0 {
+					continue
+				}
+				if isHeader && headerRow < 0 && len(rows) == 0 {
+					headerRow = 0
+				}
+				rows = append(rows, cells)
+			case "table":
+				// Tabel di dalam tabel diratakan jadi teks sel induknya
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+	if len(rows) == 0 {
+		return
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	// GFM wajib punya baris header, baris pertama dipakai kalau tidak ada th
+	header := rows[0]
+	body := rows[1:]
+	if headerRow < 0 {
+		header = make([]string, columns)
+		body = rows
+	}
+
+	writeRow := func(cells []string) {
+		padded := make([]string, columns)
+		copy(padded, cells)
+		w.write("| " + strings.Join(padded, " | ") + " |\n")
+	}
+	writeRow(header)
+	separator := make([]string, columns)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+	for _, row := range body {
+		writeRow(row)
+	}
+}
+
+// inline renders node's children on a single line.
+func inline(node *html.Node, base *url.URL) string {
+	w := &markdownWriter{base: base}
+	w.children(node)
+	return strings.Join(strings.Fields(w.b.String()), " ")
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "br" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// codeLanguage membaca class language-xxx atau lang-xxx dari pre atau code di dalamnya
+func codeLanguage(node *html.Node) string {
+	candidates := []*html.Node{node}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "code" {
+			candidates = append(candidates, child)
+		}
+	}
+	for _, candidate := range candidates {
+		for _, class := range strings.Fields(attr(candidate, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if strings.HasPrefix(class, prefix) {
+					return strings.TrimPrefix(class, prefix)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func escapeURL(raw string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(raw)
+}
+
+func startsWithSpace(text string) bool {
+	return text != "" && strings.TrimLeft(text, " \t\r\n") != text
+}
+
+func endsWithSpace(text string) bool {
+	return text != "" && strings.TrimRight(text, " \t\r\n") != text
+}
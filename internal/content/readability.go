@@ -0,0 +1,372 @@
+package content
+
+import (
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Article is the main content of a page with the boilerplate stripped.
+type Article struct {
+	Title       string
+	Byline      string
+	PublishedAt *time.Time
+	LeadImage   string
+	Excerpt     string
+	Text        string
+	Markdown    string
+	WordCount   int
+}
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)\b(ad|ads|adv|advert|advertisement|banner|breadcrumbs?|combx|comment|comments|community|cookie|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|modal|nav|navbar|newsletter|outbrain|pager|pagination|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|sponsored|subscribe|supplemental|taboola|tags|tool|widget)\b`)
+	maybeCandidate     = regexp.MustCompile(`(?i)\b(and|article|body|column|content|main|shadow|story|entry|post|text)\b`)
+	positiveWeight     = regexp.MustCompile(`(?i)\b(article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story)\b`)
+	negativeWeight     = regexp.MustCompile(`(?i)\b(hidden|banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget)\b`)
+	bylinePattern      = regexp.MustCompile(`(?i)\b(byline|author|writtenby|p-author)\b`)
+)
+
+var publishedDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// Extract finds the main content of doc in the spirit of Mozilla's
+// Readability. doc is cloned first so the caller's document stays usable.
+func Extract(doc *goquery.Document, pageUrl string) *Article {
+	base, _ := url.Parse(pageUrl)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok && base != nil {
+		if resolved, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			base = resolved
+		}
+	}
+
+	article := &Article{
+		Title:       articleTitle(doc),
+		Byline:      articleByline(doc),
+		PublishedAt: articlePublishedAt(doc),
+		LeadImage:   resolve(base, firstMeta(doc, `meta[property="og:image"]`, `meta[name="twitter:image"]`, `meta[property="twitter:image"]`)),
+		Excerpt:     firstMeta(doc, `meta[name="description"]`, `meta[property="og:description"]`, `meta[name="twitter:description"]`),
+	}
+
+	clean := goquery.CloneDocument(doc)
+	removeBoilerplate(clean)
+	main := topCandidate(clean)
+	cleanContent(main)
+
+	if article.LeadImage == "" {
+		if src, ok := main.Find("img[src]").First().Attr("src"); ok {
+			article.LeadImage = resolve(base, src)
+		}
+	}
+
+	article.Markdown = ToMarkdown(main, base)
+	article.Text = ToText(main)
+	article.WordCount = len(strings.Fields(article.Text))
+
+	if article.Excerpt == "" {
+		main.Find("p").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			text := normalizeSpace(s.Text())
+			if len(text) >= 80 {
+				article.Excerpt = text
+				return false
+			}
+			return true
+		})
+	}
+	return article
+}
+
+func removeBoilerplate(doc *goquery.Document) {
+	doc.Find("script, style, noscript, template, iframe, embed, object, svg, canvas, form, button, input, select, textarea, nav, footer, aside, dialog").Remove()
+	doc.Find(`[role="navigation"], [role="banner"], [role="contentinfo"], [role="complementary"], [role="dialog"], [aria-hidden="true"], [hidden]`).Remove()
+
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "html", "body", "article", "main", "a":
+			return
+		}
+		match := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyCandidates.MatchString(match) && !maybeCandidate.MatchString(match) && !hasAncestor(s, "table", "code", "pre") {
+			s.Remove()
+		}
+	})
+
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		for _, attr := range []string{"onclick", "onload", "onerror", "onmouseover", "style"} {
+			s.RemoveAttr(attr)
+		}
+	})
+}
+
+// topCandidate memberi skor pada induk paragraf seperti Readability: panjang
+// teks, jumlah koma, bobot class/id, lalu dikurangi kepadatan link
+func topCandidate(doc *goquery.Document) *goquery.Selection {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node
+
+	addScore := func(node *html.Node, score float64) {
+		if node == nil || node.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[node]; !ok {
+			scores[node] = classWeight(goquery.NewDocumentFromNode(node).Selection) + tagWeight(node.Data)
+			order = append(order, node)
+		}
+		scores[node] += score
+	}
+
+	doc.Find("p, pre, td, blockquote, section, h2, h3").Each(func(_ int, s *goquery.Selection) {
+		text := normalizeSpace(s.Text())
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+
+		parent := s.Parent()
+		if parent.Length() == 0 {
+			return
+		}
+		addScore(parent.Get(0), score)
+		if grand := parent.Parent(); grand.Length() > 0 {
+			addScore(grand.Get(0), score/2)
+			if great := grand.Parent(); great.Length() > 0 {
+				addScore(great.Get(0), score/6)
+			}
+		}
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, node := range order {
+		s := goquery.NewDocumentFromNode(node).Selection
+		score := scores[node] * (1 - linkDensity(s))
+		scores[node] = score
+		if best == nil || score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+
+	if best == nil {
+		if main := doc.Find("article, main, [itemprop='articleBody']").First(); main.Length() > 0 {
+			return main
+		}
+		return doc.Find("body").First()
+	}
+
+	// Sibling dengan skor cukup tinggi ikut digabung, mis. artikel yang dipecah ke beberapa div
+	threshold := math.Max(10, bestScore*0.2)
+	wrapper := &html.Node{Type: html.ElementNode, Data: "div"}
+	for sibling := firstElementSibling(best); sibling != nil; sibling = nextElementSibling(sibling) {
+		keep := sibling == best
+		if !keep {
+			if score, ok := scores[sibling]; ok && score >= threshold {
+				keep = true
+			} else if sibling.Data == "p" {
+				s := goquery.NewDocumentFromNode(sibling).Selection
+				text := normalizeSpace(s.Text())
+				keep = len(text) > 80 && linkDensity(s) < 0.25
+			}
+		}
+		if keep {
+			wrapper.AppendChild(cloneNode(sibling))
+		}
+	}
+	return goquery.NewDocumentFromNode(wrapper).Selection
+}
+
+func cleanContent(main *goquery.Selection) {
+	// Blok yang isinya kebanyakan link biasanya daftar tautan terkait
+	main.Find("div, section, ul, ol, table").Each(func(_ int, s *goquery.Selection) {
+		if hasAncestor(s, "pre", "code") {
+			return
+		}
+		text := normalizeSpace(s.Text())
+		if s.Find("img, pre, table").Length() > 0 && goquery.NodeName(s) != "ul" && goquery.NodeName(s) != "ol" {
+			return
+		}
+		density := linkDensity(s)
+		if (len(text) < 25 && s.Find("img").Length() == 0) || (density > 0.5 && len(text) < 500) {
+			if classWeight(s) < 0 || density > 0.5 || text == "" {
+				s.Remove()
+			}
+		}
+	})
+
+	main.Find("p").Each(func(_ int, s *goquery.Selection) {
+		if normalizeSpace(s.Text()) == "" && s.Find("img").Length() == 0 {
+			s.Remove()
+		}
+	})
+}
+
+func articleTitle(doc *goquery.Document) string {
+	if title := firstMeta(doc, `meta[property="og:title"]`, `meta[name="twitter:title"]`); title != "" {
+		return title
+	}
+	if h1 := doc.Find("h1"); h1.Length() == 1 {
+		return normalizeSpace(h1.Text())
+	}
+	return normalizeSpace(doc.Find("title").First().Text())
+}
+
+func articleByline(doc *goquery.Document) string {
+	if author := firstMeta(doc, `meta[name="author"]`, `meta[property="article:author"]`, `meta[name="byl"]`); author != "" && !strings.HasPrefix(author, "http") {
+		return author
+	}
+
+	var byline string
+	doc.Find(`[rel="author"], [itemprop="author"], [class], [id]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if s.AttrOr("rel", "") != "author" && s.AttrOr("itemprop", "") != "author" &&
+			!bylinePattern.MatchString(s.AttrOr("class", "")+" "+s.AttrOr("id", "")) {
+			return true
+		}
+		if name := s.Find(`[itemprop="name"]`).First(); name.Length() > 0 {
+			s = name
+		}
+		text := normalizeSpace(s.Text())
+		if text != "" && len(text) < 100 {
+			byline = text
+			return false
+		}
+		return true
+	})
+	return byline
+}
+
+func articlePublishedAt(doc *goquery.Document) *time.Time {
+	candidates := []string{
+		firstMeta(doc, `meta[property="article:published_time"]`, `meta[itemprop="datePublished"]`, `meta[name="date"]`, `meta[name="pubdate"]`, `meta[name="publish-date"]`, `meta[name="dc.date"]`, `meta[name="DC.date.issued"]`),
+		strings.TrimSpace(doc.Find(`[itemprop="datePublished"]`).First().AttrOr("datetime", "")),
+		strings.TrimSpace(doc.Find("time[datetime]").First().AttrOr("datetime", "")),
+	}
+	for _, raw := range candidates {
+		if raw == "" {
+			continue
+		}
+		for _, layout := range publishedDateLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+func firstMeta(doc *goquery.Document, selectors ...string) string {
+	for _, selector := range selectors {
+		if content := strings.TrimSpace(doc.Find(selector).First().AttrOr("content", "")); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+func classWeight(s *goquery.Selection) float64 {
+	weight := 0.0
+	for _, value := range []string{s.AttrOr("class", ""), s.AttrOr("id", "")} {
+		if value == "" {
+			continue
+		}
+		if negativeWeight.MatchString(value) {
+			weight -= 25
+		}
+		if positiveWeight.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func tagWeight(tag string) float64 {
+	switch tag {
+	case "article":
+		return 10
+	case "div", "main", "section":
+		return 5
+	case "pre", "td", "blockquote":
+		return 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		return -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		return -5
+	}
+	return 0
+}
+
+func linkDensity(s *goquery.Selection) float64 {
+	textLength := len(normalizeSpace(s.Text()))
+	if textLength == 0 {
+		return 0
+	}
+	linkLength := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linkLength += len(normalizeSpace(a.Text()))
+	})
+	return float64(linkLength) / float64(textLength)
+}
+
+func hasAncestor(s *goquery.Selection, tags ...string) bool {
+	return s.ParentsFiltered(strings.Join(tags, ", ")).Length() > 0
+}
+
+func firstElementSibling(node *html.Node) *html.Node {
+	if node.Parent == nil {
+		return node
+	}
+	for sibling := node.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type == html.ElementNode {
+			return sibling
+		}
+	}
+	return node
+}
+
+func nextElementSibling(node *html.Node) *html.Node {
+	for sibling := node.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type == html.ElementNode {
+			return sibling
+		}
+	}
+	return nil
+}
+
+func cloneNode(node *html.Node) *html.Node {
+	clone := &html.Node{
+		Type:      node.Type,
+		DataAtom:  node.DataAtom,
+		Data:      node.Data,
+		Namespace: node.Namespace,
+		Attr:      append([]html.Attribute(nil), node.Attr...),
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		clone.AppendChild(cloneNode(child))
+	}
+	return clone
+}
+
+func resolve(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if base == nil || raw == "" {
+		return raw
+	}
+	resolved, err := base.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return resolved.String()
+}
+
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,41 @@
+package dedup
+
+import "sync"
+
+// MemoryVisitedSet keeps the visited set in process memory, for tests and
+// single-node runs without Postgres.
+type MemoryVisitedSet struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]struct{}
+}
+
+func NewMemoryVisitedSet() *MemoryVisitedSet {
+	return &MemoryVisitedSet{
+		sessions: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryVisitedSet) MarkVisited(sessionID, url string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	visited, ok := s.sessions[sessionID]
+	if !ok {
+		visited = make(map[string]struct{})
+		s.sessions[sessionID] = visited
+	}
+
+	if _, seen := visited[url]; seen {
+		return false, nil
+	}
+	visited[url] = struct{}{}
+	return true, nil
+}
+
+func (s *MemoryVisitedSet) Forget(sessionID, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions[sessionID], url)
+	return nil
+}
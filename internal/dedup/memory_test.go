@@ -0,0 +1,112 @@
+package dedup
+
+import (
+	"sync"
+	"testing"
+)
+
+// crawl walks links breadth first the way the worker enqueues children: a
+// URL is only enqueued by the caller that marks it first.
+func crawl(t *testing.T, set VisitedSet, sessionID, root string, links map[string][]string) map[string]int {
+	t.Helper()
+
+	enqueued := map[string]int{}
+	if added, err := set.MarkVisited(sessionID, root); err != nil || !added {
+		t.Fatalf("root %s not added: added=%v err=%v", root, added, err)
+	}
+	enqueued[root]++
+
+	queue := []string{root}
+	for len(queue) > 0 {
+		page := queue[0]
+		queue = queue[1:]
+		for _, href := range links[page] {
+			added, err := set.MarkVisited(sessionID, href)
+			if err != nil {
+				t.Fatalf("MarkVisited(%s): %v", href, err)
+			}
+			if added {
+				enqueued[href]++
+				queue = append(queue, href)
+			}
+		}
+	}
+	return enqueued
+}
+
+func TestSiblingsAndCyclesEnqueueOnce(t *testing.T) {
+	links := map[string][]string{
+		"https://example.com/":  {"https://example.com/a", "https://example.com/b", "https://example.com/a"},
+		"https://example.com/a": {"https://example.com/", "https://example.com/b", "https://example.com/c"},
+		"https://example.com/b": {"https://example.com/a", "https://example.com/c"},
+		"https://example.com/c": {"https://example.com/", "https://example.com/c"},
+	}
+
+	enqueued := crawl(t, NewMemoryVisitedSet(), "session-1", "https://example.com/", links)
+
+	if len(enqueued) != 4 {
+		t.Fatalf("enqueued %d urls, want 4: %v", len(enqueued), enqueued)
+	}
+	for url, count := range enqueued {
+		if count != 1 {
+			t.Errorf("%s enqueued %d times, want 1", url, count)
+		}
+	}
+}
+
+func TestSessionsAreIndependent(t *testing.T) {
+	set := NewMemoryVisitedSet()
+	url := "https://example.com/"
+
+	for _, session := range []string{"session-1", "session-2"} {
+		added, err := set.MarkVisited(session, url)
+		if err != nil || !added {
+			t.Fatalf("first mark in %s: added=%v err=%v", session, added, err)
+		}
+	}
+	if added, _ := set.MarkVisited("session-1", url); added {
+		t.Fatal("second mark in session-1 reported added")
+	}
+}
+
+func TestForgetAllowsEnqueueAgain(t *testing.T) {
+	set := NewMemoryVisitedSet()
+	url := "https://example.com/a"
+
+	set.MarkVisited("session-1", url)
+	if err := set.Forget("session-1", url); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if added, _ := set.MarkVisited("session-1", url); !added {
+		t.Fatal("url not added again after Forget")
+	}
+	// Forget pada session yang belum pernah ada tidak boleh panic
+	if err := set.Forget("unknown", url); err != nil {
+		t.Fatalf("Forget on unknown session: %v", err)
+	}
+}
+
+func TestConcurrentMarkAddsOnce(t *testing.T) {
+	set := NewMemoryVisitedSet()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, _ := set.MarkVisited("session-1", "https://example.com/")
+			if ok {
+				mu.Lock()
+				added++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if added != 1 {
+		t.Fatalf("%d goroutines added the url, want 1", added)
+	}
+}
@@ -0,0 +1,36 @@
+package dedup
+
+import (
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresVisitedSet shares the visited set across every worker through the
+// unique index on (session_id, url).
+type PostgresVisitedSet struct {
+	DB *gorm.DB
+}
+
+func NewPostgresVisitedSet(db *gorm.DB) *PostgresVisitedSet {
+	return &PostgresVisitedSet{
+		DB: db,
+	}
+}
+
+func (s *PostgresVisitedSet) MarkVisited(sessionID, url string) (bool, error) {
+	visited := models.VisitedURL{
+		SessionID: sessionID,
+		URL:       url,
+	}
+
+	result := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&visited)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *PostgresVisitedSet) Forget(sessionID, url string) error {
+	return s.DB.Where("session_id = ? AND url = ?", sessionID, url).Delete(&models.VisitedURL{}).Error
+}
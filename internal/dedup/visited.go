@@ -0,0 +1,12 @@
+package dedup
+
+// VisitedSet remembers which URLs have already been enqueued in a crawl
+// session so sibling pages linking to the same URL don't crawl it twice.
+type VisitedSet interface {
+	// MarkVisited records url for the session and reports whether it was not
+	// seen before. Only the caller that gets true should enqueue the URL.
+	MarkVisited(sessionID, url string) (bool, error)
+	// Forget removes url again, for a caller that marked it but could not
+	// enqueue it after all.
+	Forget(sessionID, url string) error
+}
@@ -0,0 +1,241 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	OpEqual  = ' '
+	OpDelete = '-'
+	OpInsert = '+'
+)
+
+// maxEditDistance membatasi memori Myers, di atas ini teks dianggap diganti total
+const maxEditDistance = 4000
+
+// Edit is one line of an edit script.
+type Edit struct {
+	Op   byte
+	Line string
+}
+
+// Lines returns the shortest line edit script turning a into b.
+func Lines(a, b string) []Edit {
+	return editScript(splitLines(a), splitLines(b))
+}
+
+// Stats counts inserted and deleted lines of an edit script.
+func Stats(edits []Edit) (inserted, deleted int) {
+	for _, e := range edits {
+		switch e.Op {
+		case OpInsert:
+			inserted++
+		case OpDelete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// Unified formats the difference between a and b as a unified diff with
+// context lines around each hunk. Equal texts give an empty string.
+func Unified(a, b string, context int) string {
+	return Format(Lines(a, b), context)
+}
+
+// Format renders an edit script from Lines as unified diff hunks.
+func Format(edits []Edit, context int) string {
+	type numbered struct {
+		Edit
+		oldLine, newLine int
+	}
+	lines := make([]numbered, 0, len(edits))
+	oldLine, newLine := 1, 1
+	for _, e := range edits {
+		lines = append(lines, numbered{e, oldLine, newLine})
+		switch e.Op {
+		case OpEqual:
+			oldLine++
+			newLine++
+		case OpDelete:
+			oldLine++
+		case OpInsert:
+			newLine++
+		}
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].Op == OpEqual {
+			i++
+			continue
+		}
+
+		// Satu hunk berlanjut selama jarak antar perubahan tidak lebih dari 2*context
+		start := max(i-context, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Op == OpEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			end = run
+		}
+
+		oldStart, newStart := lines[start].oldLine, lines[start].newLine
+		oldCount, newCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.Op != OpInsert {
+				oldCount++
+			}
+			if l.Op != OpDelete {
+				newCount++
+			}
+		}
+		// Hunk kosong di satu sisi memakai nomor baris sebelumnya, sama seperti diff -u
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, l := range lines[start:end] {
+			out.WriteByte(l.Op)
+			out.WriteString(l.Line)
+			out.WriteByte('\n')
+		}
+		i = end
+	}
+	return out.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// editScript trims the common prefix and suffix and runs Myers' algorithm on
+// the rest.
+func editScript(a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{OpEqual, line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{OpEqual, line})
+	}
+	return edits
+}
+
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	// trace[d] menyimpan v untuk k di [-d, d] setelah langkah d
+	var trace [][]int
+
+	limit := min(n+m, maxEditDistance)
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return replaceAll(a, b)
+}
+
+func backtrack(a, b []string, trace [][]int) []Edit {
+	x, y := len(a), len(b)
+	reversed := make([]Edit, 0, x+y)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Edit{OpEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Edit{OpInsert, b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, Edit{OpDelete, a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Edit{OpEqual, a[x-1]})
+		x--
+		y--
+	}
+
+	edits := make([]Edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+func replaceAll(a, b []string) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, Edit{OpDelete, line})
+	}
+	for _, line := range b {
+		edits = append(edits, Edit{OpInsert, line})
+	}
+	return edits
+}
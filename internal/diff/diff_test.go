@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both sides from an edit script.
+func apply(edits []Edit) (old, new []string) {
+	for _, e := range edits {
+		if e.Op != OpInsert {
+			old = append(old, e.Line)
+		}
+		if e.Op != OpDelete {
+			new = append(new, e.Line)
+		}
+	}
+	return old, new
+}
+
+func lcs(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
+
+func TestLinesIsShortestEditScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomText := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomText(), randomText()
+		edits := Lines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+
+		old, new := apply(edits)
+		if strings.Join(old, "\n") != strings.Join(a, "\n") || strings.Join(new, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("edits of %q -> %q rebuild %q -> %q", a, b, old, new)
+		}
+		inserted, deleted := Stats(edits)
+		if want := len(a) + len(b) - 2*lcs(a, b); inserted+deleted != want {
+			t.Fatalf("edits of %q -> %q change %d lines, want %d", a, b, inserted+deleted, want)
+		}
+	}
+}
+
+func TestLinesTrailingNewline(t *testing.T) {
+	edits := Lines("a\nb\n", "a\nb")
+	if inserted, deleted := Stats(edits); inserted != 0 || deleted != 0 {
+		t.Fatalf("trailing newline counted as a change: %+v", edits)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb",
+			b:    "a\nb",
+			want: "",
+		},
+		{
+			name:    "separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			b:       "1\ntwo\n3\n4\n5\n6\n7\n8\nnine\n10",
+			context: 1,
+			want: "@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n" +
+				"@@ -8,3 +8,3 @@\n 8\n-9\n+nine\n 10\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			b:       "1\ntwo\n3\n4\n5\n6\n7\n8\nnine\n10",
+			context: 3,
+			want:    "@@ -1,10 +1,10 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+nine\n 10\n",
+		},
+		{
+			name:    "insert without context",
+			a:       "a\nb",
+			b:       "a\nx\nb",
+			context: 0,
+			want:    "@@ -1,0 +2,1 @@\n+x\n",
+		},
+		{
+			name:    "delete without context",
+			a:       "a\nx\nb",
+			b:       "a\nb",
+			context: 0,
+			want:    "@@ -2,1 +1,0 @@\n-x\n",
+		},
+		{
+			name:    "from empty",
+			a:       "",
+			b:       "a\nb",
+			context: 3,
+			want:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "to empty",
+			a:       "a\nb",
+			b:       "",
+			context: 3,
+			want:    "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "context clipped at the end",
+			a:       "1\n2\n3",
+			b:       "1\n2\nthree",
+			context: 3,
+			want:    "@@ -1,3 +1,3 @@\n 1\n 2\n-3\n+three\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.a, tt.b, tt.context); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+// Fields compares two ParsedData maps. Nested objects are compared key by key
+// and reported with a dotted path, lists are compared as a whole. Values go
+// through a JSON round trip first, so an int64 fresh from extraction equals
+// the float64 read back from jsonb.
+func Fields(old, new map[string]interface{}) []models.FieldChange {
+	changes := make([]models.FieldChange, 0)
+	compareObjects("", normalize(old), normalize(new), &changes)
+	return changes
+}
+
+func compareObjects(prefix string, old, new interface{}, changes *[]models.FieldChange) {
+	oldMap, _ := old.(map[string]interface{})
+	newMap, _ := new.(map[string]interface{})
+
+	keys := make(map[string]bool, len(oldMap)+len(newMap))
+	for key := range oldMap {
+		keys[key] = true
+	}
+	for key := range newMap {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		oldValue, inOld := oldMap[key]
+		newValue, inNew := newMap[key]
+
+		switch {
+		case !inOld:
+			*changes = append(*changes, models.FieldChange{Field: path, Change: models.FieldAdded, New: newValue})
+		case !inNew:
+			*changes = append(*changes, models.FieldChange{Field: path, Change: models.FieldRemoved, Old: oldValue})
+		default:
+			_, oldIsObject := oldValue.(map[string]interface{})
+			_, newIsObject := newValue.(map[string]interface{})
+			if oldIsObject && newIsObject {
+				compareObjects(path, oldValue, newValue, changes)
+				continue
+			}
+			if !reflect.DeepEqual(oldValue, newValue) {
+				*changes = append(*changes, models.FieldChange{Field: path, Change: models.FieldChanged, Old: oldValue, New: newValue})
+			}
+		}
+	}
+}
+
+func normalize(value map[string]interface{}) interface{} {
+	if value == nil {
+		return map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
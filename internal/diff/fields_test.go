@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+func TestFields(t *testing.T) {
+	old := map[string]interface{}{
+		"title":   "Old title",
+		"price":   float64(1250),
+		"stock":   int64(3),
+		"tags":    []interface{}{"a", "b"},
+		"removed": "gone",
+		"seller": map[string]interface{}{
+			"name":   "Toko A",
+			"rating": 4.5,
+		},
+	}
+	new := map[string]interface{}{
+		"title": "New title",
+		// int64 dari ekstraksi sama dengan float64 hasil baca jsonb
+		"price": int64(1250),
+		"stock": int64(3),
+		"tags":  []interface{}{"a", "c"},
+		"added": true,
+		"seller": map[string]interface{}{
+			"name":   "Toko A",
+			"rating": 4.8,
+			"city":   "Bandung",
+		},
+	}
+
+	want := []models.FieldChange{
+		{Field: "added", Change: models.FieldAdded, New: true},
+		{Field: "removed", Change: models.FieldRemoved, Old: "gone"},
+		{Field: "seller.city", Change: models.FieldAdded, New: "Bandung"},
+		{Field: "seller.rating", Change: models.FieldChanged, Old: 4.5, New: 4.8},
+		{Field: "tags", Change: models.FieldChanged, Old: []interface{}{"a", "b"}, New: []interface{}{"a", "c"}},
+		{Field: "title", Change: models.FieldChanged, Old: "Old title", New: "New title"},
+	}
+	if got := Fields(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestFieldsObjectReplacedByValue(t *testing.T) {
+	old := map[string]interface{}{"seller": map[string]interface{}{"name": "Toko A"}}
+	new := map[string]interface{}{"seller": "Toko A"}
+
+	want := []models.FieldChange{
+		{Field: "seller", Change: models.FieldChanged, Old: map[string]interface{}{"name": "Toko A"}, New: "Toko A"},
+	}
+	if got := Fields(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestFieldsNilMaps(t *testing.T) {
+	if got := Fields(nil, nil); len(got) != 0 {
+		t.Errorf("nil maps differ: %+v", got)
+	}
+
+	want := []models.FieldChange{{Field: "title", Change: models.FieldAdded, New: "Hello"}}
+	if got := Fields(nil, map[string]interface{}{"title": "Hello"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package document
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ToUTF8 transcodes body to UTF-8 and returns the name of the encoding it was
+// read as. Detection follows the HTML rules: BOM, then the charset parameter
+// of contentType, then <meta charset> or http-equiv in the first 1024 bytes,
+// and finally a UTF-8 or windows-1252 guess. The result never contains
+// invalid UTF-8 or NUL bytes, which Postgres refuses in text and jsonb.
+func ToUTF8(body []byte, contentType string) ([]byte, string) {
+	enc, name, _ := charset.DetermineEncoding(body, contentType)
+
+	decoded := body
+	if name != "utf-8" {
+		if converted, err := enc.NewDecoder().Bytes(body); err == nil {
+			decoded = converted
+		} else {
+			// Gagal transcode, anggap UTF-8 dan bersihkan byte yang rusak
+			name = "utf-8"
+		}
+	}
+
+	decoded = bytes.TrimPrefix(decoded, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(decoded) {
+		decoded = bytes.ToValidUTF8(decoded, []byte("�"))
+	}
+	if bytes.IndexByte(decoded, 0) >= 0 {
+		decoded = bytes.ReplaceAll(decoded, []byte{0}, nil)
+	}
+	return decoded, strings.ToLower(name)
+}
@@ -0,0 +1,47 @@
+package document
+
+import "testing"
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+		wantName    string
+	}{
+		{"plain utf-8", "<p>café</p>", "text/html", "<p>café</p>", "utf-8"},
+		// ASCII murni tidak punya petunjuk, tebakan HTML jatuh ke windows-1252
+		{"ascii", "<p>hello</p>", "text/html", "<p>hello</p>", "windows-1252"},
+
+		// BOM menang atas charset di header
+		{"utf-8 bom", "\xef\xbb\xbf<p>café</p>", "text/html; charset=iso-8859-1", "<p>café</p>", "utf-8"},
+		{"utf-16le bom", "\xff\xfe<\x00p\x00>\x00\xe9\x00", "text/html", "<p>é", "utf-16le"},
+		{"utf-16be bom", "\xfe\xff\x00<\x00p\x00>\x00\xe9", "text/html", "<p>é", "utf-16be"},
+
+		{"charset parameter", "<p>caf\xe9</p>", "text/html; charset=ISO-8859-1", "<p>café</p>", "windows-1252"},
+		{"charset parameter quoted", "<p>\xb1</p>", `text/html; charset="iso-8859-2"`, "<p>ą</p>", "iso-8859-2"},
+		{"charset parameter over meta", `<meta charset="shift_jis"><p>caf` + "\xe9</p>", "text/html; charset=windows-1252", `<meta charset="shift_jis"><p>café</p>`, "windows-1252"},
+
+		{"meta charset", `<html><head><meta charset="iso-8859-2"></head><p>` + "\xb9</p>", "text/html", `<html><head><meta charset="iso-8859-2"></head><p>š</p>`, "iso-8859-2"},
+		{"meta http-equiv", `<meta http-equiv="Content-Type" content="text/html; charset=shift_jis"><p>` + "\x93\xfa</p>", "", `<meta http-equiv="Content-Type" content="text/html; charset=shift_jis"><p>日</p>`, "shift_jis"},
+
+		// Tanpa deklarasi apa pun, byte yang bukan UTF-8 dibaca sebagai windows-1252
+		{"windows-1252 fallback", "<p>\x93quoted\x94 caf\xe9</p>", "text/html", "<p>“quoted” café</p>", "windows-1252"},
+		{"invalid utf-8 declared", "<p>caf\xe9</p>", "text/html; charset=utf-8", "<p>caf�</p>", "utf-8"},
+
+		{"nul bytes stripped", "<p>a\x00b</p>", "text/html; charset=utf-8", "<p>ab</p>", "utf-8"},
+		{"nul after transcode", "<p>caf\xe9\x00</p>", "text/html; charset=iso-8859-1", "<p>café</p>", "windows-1252"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, name := ToUTF8([]byte(tt.body), tt.contentType)
+			if string(got) != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if name != tt.wantName {
+				t.Errorf("encoding = %q, want %q", name, tt.wantName)
+			}
+		})
+	}
+}
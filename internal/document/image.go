@@ -0,0 +1,55 @@
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ImageSize returns the pixel dimensions of a GIF, JPEG, PNG or WebP image
+// without decoding the pixels. ok is false for formats it can't read.
+func ImageSize(body []byte) (width, height int, ok bool) {
+	if config, _, err := image.DecodeConfig(bytes.NewReader(body)); err == nil {
+		return config.Width, config.Height, true
+	}
+	return webpSize(body)
+}
+
+// webpSize membaca header VP8, VP8L dan VP8X karena decoder WebP tidak ada di stdlib
+func webpSize(body []byte) (int, int, bool) {
+	if len(body) < 30 || string(body[0:4]) != "RIFF" || string(body[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+
+	chunk := body[12:]
+	switch string(chunk[0:4]) {
+	case "VP8 ":
+		// Frame tag 3 byte lalu start code 9d 01 2a
+		frame := chunk[8:]
+		if len(frame) < 10 || frame[3] != 0x9d || frame[4] != 0x01 || frame[5] != 0x2a {
+			return 0, 0, false
+		}
+		width := int(binary.LittleEndian.Uint16(frame[6:8]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(frame[8:10]) & 0x3fff)
+		return width, height, true
+	case "VP8L":
+		data := chunk[8:]
+		if len(data) < 5 || data[0] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(data[1:5])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8X":
+		data := chunk[8:]
+		if len(data) < 10 {
+			return 0, 0, false
+		}
+		width := int(data[4]) | int(data[5])<<8 | int(data[6])<<16
+		height := int(data[7]) | int(data[8])<<8 | int(data[9])<<16
+		return width + 1, height + 1, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,459 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// PDFInfo is what the worker keeps from a PDF: the document information
+// dictionary, the page count and a best-effort text layer.
+type PDFInfo struct {
+	Title     string
+	Author    string
+	Subject   string
+	Keywords  string
+	Creator   string
+	Producer  string
+	CreatedAt *time.Time
+	PageCount int
+	Encrypted bool
+	Text      string
+}
+
+const (
+	maxPDFStreamBytes = 20 << 20
+	// maxPDFInflatedBytes membatasi total hasil inflate satu dokumen
+	maxPDFInflatedBytes = 64 << 20
+)
+
+var (
+	pdfStreamStart = regexp.MustCompile(`stream\r?\n`)
+	pdfObjectStart = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfInfoRef     = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	pdfPageCount   = regexp.MustCompile(`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`)
+	pdfDate        = regexp.MustCompile(`^D?:?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz+\-])?(\d{2})?'?(\d{2})?`)
+)
+
+// ParsePDF reads a PDF without a full object model: it inflates the Flate
+// streams, pulls text from the content stream operators and reads the Info
+// dictionary. Text in fonts without a usable encoding (CID fonts without
+// ToUnicode support) comes out garbled or empty.
+func ParsePDF(body []byte) *PDFInfo {
+	info := &PDFInfo{}
+	streams := pdfStreams(body)
+
+	info.Encrypted = bytes.Contains(body, []byte("/Encrypt"))
+	info.PageCount = pdfPages(body, streams)
+	pdfMetadata(body, streams, info)
+
+	if info.Encrypted {
+		return info
+	}
+
+	var text strings.Builder
+	for _, stream := range streams {
+		if !bytes.Contains(stream, []byte("BT")) {
+			continue
+		}
+		if extracted := pdfContentText(stream); strings.TrimSpace(extracted) != "" {
+			text.WriteString(extracted)
+			text.WriteString("\n")
+		}
+	}
+	info.Text = normalizePDFText(text.String())
+	return info
+}
+
+// pdfStreams returns the decoded content of every stream the reader can
+// decode, unfiltered or FlateDecode. Image filters are skipped. Flate
+// streams stop being inflated once the document used maxPDFInflatedBytes.
+func pdfStreams(body []byte) [][]byte {
+	var streams [][]byte
+	remaining := int64(maxPDFInflatedBytes)
+	for _, loc := range pdfStreamStart.FindAllIndex(body, -1) {
+		// "endstream" sendiri juga cocok dengan regex, lewati
+		if loc[0] >= 3 && string(body[loc[0]-3:loc[0]]) == "end" {
+			continue
+		}
+		start := loc[1]
+		end := bytes.Index(body[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		raw := body[start : start+end]
+
+		dictStart := bytes.LastIndex(body[:loc[0]], []byte("obj"))
+		if dictStart < 0 {
+			continue
+		}
+		dict := body[dictStart:loc[0]]
+
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")) && !bytes.Contains(dict, []byte("/DCTDecode")):
+			if remaining <= 0 {
+				continue
+			}
+			reader, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			decoded, _ := io.ReadAll(io.LimitReader(reader, min(maxPDFStreamBytes, remaining)))
+			reader.Close()
+			remaining -= int64(len(decoded))
+			if len(decoded) > 0 {
+				streams = append(streams, decoded)
+			}
+		case !bytes.Contains(dict, []byte("/Filter")):
+			streams = append(streams, raw)
+		}
+	}
+	return streams
+}
+
+func pdfPages(body []byte, streams [][]byte) int {
+	count := 0
+	for _, data := range append([][]byte{body}, streams...) {
+		for _, match := range pdfPageCount.FindAllSubmatch(data, -1) {
+			value := match[1]
+			if len(value) == 0 {
+				value = match[2]
+			}
+			// Pages root memegang jumlah terbesar, node anak hanya sebagian
+			if n, err := strconv.Atoi(string(value)); err == nil && n > count {
+				count = n
+			}
+		}
+	}
+	return count
+}
+
+func pdfMetadata(body []byte, streams [][]byte, info *PDFInfo) {
+	ref := pdfInfoRef.FindSubmatch(body)
+	if ref == nil {
+		return
+	}
+
+	dict := pdfObject(body, string(ref[1]), string(ref[2]))
+	if dict == nil {
+		// Info bisa berada di object stream, cari di stream yang sudah di-inflate
+		for _, stream := range streams {
+			if bytes.Contains(stream, []byte("/Producer")) || bytes.Contains(stream, []byte("/CreationDate")) {
+				dict = stream
+				break
+			}
+		}
+	}
+	if dict == nil {
+		return
+	}
+
+	info.Title = pdfDictString(dict, "Title")
+	info.Author = pdfDictString(dict, "Author")
+	info.Subject = pdfDictString(dict, "Subject")
+	info.Keywords = pdfDictString(dict, "Keywords")
+	info.Creator = pdfDictString(dict, "Creator")
+	info.Producer = pdfDictString(dict, "Producer")
+	info.CreatedAt = parsePDFDate(pdfDictString(dict, "CreationDate"))
+}
+
+func pdfObject(body []byte, number, generation string) []byte {
+	for _, loc := range pdfObjectStart.FindAllSubmatchIndex(body, -1) {
+		if string(body[loc[2]:loc[3]]) != number || string(body[loc[4]:loc[5]]) != generation {
+			continue
+		}
+		// Pastikan angka objek tidak menempel ke digit sebelumnya
+		if loc[0] > 0 && body[loc[0]-1] >= '0' && body[loc[0]-1] <= '9' {
+			continue
+		}
+		end := bytes.Index(body[loc[1]:], []byte("endobj"))
+		if end < 0 {
+			return body[loc[1]:]
+		}
+		return body[loc[1] : loc[1]+end]
+	}
+	return nil
+}
+
+// pdfDictString reads the string value of /key, literal or hex.
+func pdfDictString(dict []byte, key string) string {
+	pattern := regexp.MustCompile(`/` + key + `\s*([(<])`)
+	loc := pattern.FindSubmatchIndex(dict)
+	if loc == nil {
+		return ""
+	}
+	start := loc[2]
+	var raw []byte
+	if dict[start] == '(' {
+		raw, _ = readLiteralString(dict, start)
+	} else {
+		raw, _ = readHexString(dict, start)
+	}
+	return strings.TrimSpace(decodePDFString(raw))
+}
+
+// pdfContentText walks the operators of a content stream and prints the
+// operands of the text showing operators.
+func pdfContentText(stream []byte) string {
+	var (
+		out      strings.Builder
+		operands [][]byte
+		numbers  []float64
+	)
+
+	for i := 0; i < len(stream); {
+		c := stream[i]
+		switch {
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			value, next := readLiteralString(stream, i)
+			operands = append(operands, value)
+			i = next
+		case c == '<' && i+1 < len(stream) && stream[i+1] != '<':
+			value, next := readHexString(stream, i)
+			operands = append(operands, value)
+			i = next
+		case c == '[':
+			// Array TJ: string digabung, angka kerning besar dianggap spasi
+			i++
+			var joined []byte
+			for i < len(stream) && stream[i] != ']' {
+				switch {
+				case stream[i] == '(':
+					value, next := readLiteralString(stream, i)
+					joined = append(joined, value...)
+					i = next
+				case stream[i] == '<':
+					value, next := readHexString(stream, i)
+					joined = append(joined, value...)
+					i = next
+				case stream[i] == '-' || stream[i] == '.' || (stream[i] >= '0' && stream[i] <= '9'):
+					start := i
+					for i < len(stream) && (stream[i] == '-' || stream[i] == '.' || (stream[i] >= '0' && stream[i] <= '9')) {
+						i++
+					}
+					if n, err := strconv.ParseFloat(string(stream[start:i]), 64); err == nil && n < -200 {
+						joined = append(joined, ' ')
+					}
+				default:
+					i++
+				}
+			}
+			i++
+			operands = append(operands, joined)
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			start := i
+			for i < len(stream) && (stream[i] == '-' || stream[i] == '+' || stream[i] == '.' || (stream[i] >= '0' && stream[i] <= '9')) {
+				i++
+			}
+			if n, err := strconv.ParseFloat(string(stream[start:i]), 64); err == nil {
+				numbers = append(numbers, n)
+			}
+		case isPDFRegular(c):
+			start := i
+			for i < len(stream) && isPDFRegular(stream[i]) {
+				i++
+			}
+			switch string(stream[start:i]) {
+			case "Tj", "TJ":
+				for _, operand := range operands {
+					out.WriteString(decodePDFString(operand))
+				}
+			case "'", "\"":
+				out.WriteString("\n")
+				for _, operand := range operands {
+					out.WriteString(decodePDFString(operand))
+				}
+			case "T*":
+				out.WriteString("\n")
+			case "Td", "TD":
+				if len(numbers) >= 2 && numbers[len(numbers)-1] != 0 {
+					out.WriteString("\n")
+				} else {
+					out.WriteString(" ")
+				}
+			case "ET":
+				out.WriteString("\n")
+			case "BI":
+				// Inline image: lompati data biner sampai EI
+				if end := bytes.Index(stream[i:], []byte("EI")); end >= 0 {
+					i += end + 2
+				}
+			}
+			operands = operands[:0]
+			numbers = numbers[:0]
+		default:
+			i++
+		}
+	}
+	return out.String()
+}
+
+func isPDFRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '*' || c == '\'' || c == '"'
+}
+
+func readLiteralString(data []byte, start int) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out, i + 1
+			}
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(data) {
+				return out, i
+			}
+			switch e := data[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// Baris yang disambung
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					value := 0
+					j := i
+					for ; j < len(data) && j < i+3 && data[j] >= '0' && data[j] <= '7'; j++ {
+						value = value*8 + int(data[j]-'0')
+					}
+					out = append(out, byte(value))
+					i = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(data)
+}
+
+func readHexString(data []byte, start int) ([]byte, int) {
+	end := bytes.IndexByte(data[start:], '>')
+	if end < 0 {
+		return nil, len(data)
+	}
+	var digits []byte
+	for _, c := range data[start+1 : start+end] {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		value, _ := strconv.ParseUint(string(digits[i*2:i*2+2]), 16, 8)
+		out[i] = byte(value)
+	}
+	return out, start + end + 1
+}
+
+// decodePDFString handles UTF-16BE strings (with BOM, or two byte codes
+// with a zero high byte) and falls back to Latin-1 for the rest.
+func decodePDFString(raw []byte) string {
+	utf16be := len(raw) >= 2 && raw[0] == 0xfe && raw[1] == 0xff
+	if utf16be {
+		raw = raw[2:]
+	} else if len(raw) >= 2 && len(raw)%2 == 0 {
+		utf16be = true
+		for i := 0; i < len(raw); i += 2 {
+			if raw[i] != 0 {
+				utf16be = false
+				break
+			}
+		}
+	}
+
+	if utf16be {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 0; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	runes := make([]rune, 0, len(raw))
+	for _, b := range raw {
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
+
+func normalizePDFText(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\r' || r == '\f' || r < 0x09
+		}), " ")
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// parsePDFDate membaca format D:YYYYMMDDHHmmSSOHH'mm' dari Info dictionary
+func parsePDFDate(raw string) *time.Time {
+	match := pdfDate.FindStringSubmatch(strings.TrimSpace(raw))
+	if match == nil {
+		return nil
+	}
+	number := func(value string, fallback int) int {
+		if value == "" {
+			return fallback
+		}
+		n, _ := strconv.Atoi(value)
+		return n
+	}
+
+	location := time.UTC
+	if sign := match[7]; sign == "+" || sign == "-" {
+		offset := number(match[8], 0)*3600 + number(match[9], 0)*60
+		if sign == "-" {
+			offset = -offset
+		}
+		location = time.FixedZone("", offset)
+	}
+
+	t := time.Date(number(match[1], 0), time.Month(number(match[2], 1)), number(match[3], 1),
+		number(match[4], 0), number(match[5], 0), number(match[6], 0), 0, location)
+	return &t
+}
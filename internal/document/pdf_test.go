@@ -0,0 +1,201 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("deflate: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestReadLiteralString(t *testing.T) {
+	data := []byte("(a\\(b\\) (nested) \\101\\n\\\ncont) Tj")
+
+	value, next := readLiteralString(data, 0)
+	if got, want := string(value), "a(b) (nested) A\ncont"; got != want {
+		t.Errorf("value = %q, want %q", got, want)
+	}
+	if got, want := string(data[next:]), " Tj"; got != want {
+		t.Errorf("rest = %q, want %q", got, want)
+	}
+}
+
+func TestReadHexString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"<48656C6C6F>", "Hello"},
+		{"<48 65 6c\n6c 6f>", "Hello"},
+		// Digit ganjil dilengkapi dengan 0
+		{"<414>", "A@"},
+		{"<>", ""},
+	}
+	for _, tt := range tests {
+		value, next := readHexString([]byte(tt.in), 0)
+		if string(value) != tt.want {
+			t.Errorf("readHexString(%q) = %q, want %q", tt.in, value, tt.want)
+		}
+		if next != len(tt.in) {
+			t.Errorf("readHexString(%q) next = %d, want %d", tt.in, next, len(tt.in))
+		}
+	}
+}
+
+func TestPDFDictString(t *testing.T) {
+	dict := []byte("<< /Title <FEFF00480069> /Author (Jane \\(Ed.\\)) /Subject (Caf\\351) /Keywords <00410042> >>")
+
+	tests := map[string]string{
+		"Title":    "Hi",
+		"Author":   "Jane (Ed.)",
+		"Subject":  "Café",
+		"Keywords": "AB",
+		"Creator":  "",
+	}
+	for key, want := range tests {
+		if got := pdfDictString(dict, key); got != want {
+			t.Errorf("pdfDictString(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestPDFContentText(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   string
+	}{
+		{"literal", "BT /F1 12 Tf 72 720 Td (Hello World) Tj ET", "Hello World"},
+		{"hex", "BT <48656C6C6F> Tj ET", "Hello"},
+		{"tj kerning", "BT [(Hel) -10 (lo) -500 (World)] TJ ET", "Hello World"},
+		{"tj hex", "BT [<4869> -300 (there)] TJ ET", "Hi there"},
+		{"next line", "BT (one) Tj T* (two) Tj 0 -14 Td (three) Tj ET", "one\ntwo\nthree"},
+		{"quote", "BT (one) Tj (two) ' ET", "one\ntwo"},
+		{"comment", "BT % (skipped) Tj\n(kept) Tj ET", "kept"},
+		{"inline image", "BT (before) Tj ET BI /W 1 /H 1 ID (junk) Tj EI BT (after) Tj ET", "before\nafter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizePDFText(pdfContentText([]byte(tt.stream))); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePDFDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"D:20240102030405Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"D:20240102030405+07'00'", time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 7*3600))},
+		{"D:20240102030405-05'30'", time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", -(5*3600+30*60)))},
+		{"D:2024", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"20240615", time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := parsePDFDate(tt.in)
+		if got == nil || !got.Equal(tt.want) {
+			t.Errorf("parsePDFDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "yesterday", "D:"} {
+		if got := parsePDFDate(in); got != nil {
+			t.Errorf("parsePDFDate(%q) = %v, want nil", in, got)
+		}
+	}
+}
+
+func TestParsePDFInfoInObjectStream(t *testing.T) {
+	content := deflate(t, []byte("BT /F1 12 Tf 72 720 Td (Hello) Tj [( Wor) -20 (ld)] TJ ET"))
+	objects := deflate(t, []byte("5 0 << /Title (Quarterly Report) /Author <FEFF004100630065> /Producer (Writer 1.0) /CreationDate (D:20240102030405+07'00') >>"))
+
+	var body bytes.Buffer
+	body.WriteString("%PDF-1.5\n")
+	body.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	body.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 3 >>\nendobj\n")
+	fmt.Fprintf(&body, "4 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", len(content))
+	body.Write(content)
+	body.WriteString("\nendstream\nendobj\n")
+	fmt.Fprintf(&body, "6 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Length %d /Filter /FlateDecode >>\nstream\n", len(objects))
+	body.Write(objects)
+	body.WriteString("\nendstream\nendobj\n")
+	body.WriteString("trailer\n<< /Root 1 0 R /Info 5 0 R >>\n%%EOF\n")
+
+	info := ParsePDF(body.Bytes())
+
+	if info.Title != "Quarterly Report" {
+		t.Errorf("Title = %q", info.Title)
+	}
+	if info.Author != "Ace" {
+		t.Errorf("Author = %q", info.Author)
+	}
+	if info.Producer != "Writer 1.0" {
+		t.Errorf("Producer = %q", info.Producer)
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 7*3600))
+	if info.CreatedAt == nil || !info.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt = %v, want %v", info.CreatedAt, created)
+	}
+	if info.PageCount != 3 {
+		t.Errorf("PageCount = %d, want 3", info.PageCount)
+	}
+	if info.Encrypted {
+		t.Error("Encrypted = true")
+	}
+	if info.Text != "Hello World" {
+		t.Errorf("Text = %q, want %q", info.Text, "Hello World")
+	}
+}
+
+func TestParsePDFEncryptedSkipsText(t *testing.T) {
+	body := []byte("%PDF-1.4\n1 0 obj\n<< /Length 20 >>\nstream\nBT (secret) Tj ET\nendstream\nendobj\ntrailer\n<< /Encrypt 2 0 R >>\n")
+
+	info := ParsePDF(body)
+	if !info.Encrypted {
+		t.Fatal("Encrypted = false")
+	}
+	if info.Text != "" {
+		t.Errorf("Text = %q, want empty", info.Text)
+	}
+}
+
+func TestPDFStreamsInflateBudget(t *testing.T) {
+	compressed := deflate(t, make([]byte, maxPDFStreamBytes+1024))
+
+	var body bytes.Buffer
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(&body, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", i, len(compressed))
+		body.Write(compressed)
+		body.WriteString("\nendstream\nendobj\n")
+	}
+
+	streams := pdfStreams(body.Bytes())
+
+	total := 0
+	for i, stream := range streams {
+		if len(stream) > maxPDFStreamBytes {
+			t.Errorf("stream %d inflated to %d bytes, want at most %d", i, len(stream), maxPDFStreamBytes)
+		}
+		total += len(stream)
+	}
+	if total != maxPDFInflatedBytes {
+		t.Errorf("inflated %d bytes in total, want %d", total, maxPDFInflatedBytes)
+	}
+	if len(streams) != 4 {
+		t.Errorf("inflated %d streams, want 4", len(streams))
+	}
+}
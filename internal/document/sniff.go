@@ -0,0 +1,99 @@
+package document
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	KindHTML   = "html"
+	KindPDF    = "pdf"
+	KindJSON   = "json"
+	KindImage  = "image"
+	KindText   = "text"
+	KindBinary = "binary"
+)
+
+// Sniff returns the media type of a response without parameters. The
+// Content-Type header wins unless it is missing or generic, then the body is
+// sniffed the way browsers do.
+func Sniff(header string, body []byte) string {
+	if mediaType := Declared(header); mediaType != "" {
+		return mediaType
+	}
+
+	head := bytes.TrimSpace(body[:min(len(body), 512)])
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return "application/pdf"
+	case len(head) > 0 && (head[0] == '{' || head[0] == '['):
+		return "application/json"
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+	return strings.ToLower(sniffed)
+}
+
+// Declared returns the media type of a Content-Type header, or "" when the
+// header is missing or too generic to decide without the body.
+func Declared(header string) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	mediaType = strings.ToLower(mediaType)
+	if err != nil || mediaType == "application/octet-stream" || mediaType == "binary/octet-stream" {
+		return ""
+	}
+	return mediaType
+}
+
+// KindOf groups a media type into the handlers the worker dispatches to.
+func KindOf(mediaType string) string {
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return KindHTML
+	case mediaType == "application/pdf" || mediaType == "application/x-pdf":
+		return KindPDF
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return KindJSON
+	case strings.HasPrefix(mediaType, "image/"):
+		return KindImage
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return KindText
+	default:
+		return KindBinary
+	}
+}
+
+// Extension picks the file extension a document of mediaType is stored with.
+func Extension(mediaType string) string {
+	switch mediaType {
+	case "application/pdf", "application/x-pdf":
+		return "pdf"
+	case "application/json":
+		return "json"
+	case "image/jpeg":
+		return "jpg"
+	case "image/svg+xml":
+		return "svg"
+	case "text/plain":
+		return "txt"
+	case "text/csv":
+		return "csv"
+	case "text/markdown":
+		return "md"
+	case "text/xml", "application/xml":
+		return "xml"
+	}
+	if extensions, err := mime.ExtensionsByType(mediaType); err == nil && len(extensions) > 0 {
+		return strings.TrimPrefix(extensions[0], ".")
+	}
+	if slash := strings.LastIndex(mediaType, "/"); slash >= 0 {
+		subtype := strings.TrimSuffix(mediaType[slash+1:], "+xml")
+		subtype = strings.TrimSuffix(subtype, "+json")
+		if subtype != "" && !strings.ContainsAny(subtype, ".-") {
+			return subtype
+		}
+	}
+	return "bin"
+}
@@ -0,0 +1,319 @@
+package extract
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// maxNesting membatasi kedalaman field bersarang dari request API
+const maxNesting = 5
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"02/01/2006",
+}
+
+var numberCleaner = regexp.MustCompile(`[^0-9.,+\-eE]`)
+
+// Extractor applies a validated extraction schema to parsed pages.
+type Extractor struct {
+	fields []field
+}
+
+type field struct {
+	name       string
+	selector   goquery.Matcher
+	path       []pathStep
+	extract    string
+	attr       string
+	list       bool
+	kind       string
+	typed      bool
+	dateFormat string
+	fields     []field
+}
+
+// Compile validates the schema. Errors are meant to be returned to the API
+// caller as is.
+func Compile(fields []models.ExtractField) (*Extractor, error) {
+	compiled, err := compileFields(fields, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Extractor{fields: compiled}, nil
+}
+
+func compileFields(fields []models.ExtractField, parent string, depth int) ([]field, error) {
+	if depth > maxNesting {
+		return nil, fmt.Errorf("schema field %s is nested deeper than %d levels", parent, maxNesting)
+	}
+
+	names := make(map[string]bool, len(fields))
+	compiled := make([]field, 0, len(fields))
+	for _, f := range fields {
+		path := f.Name
+		if parent != "" {
+			path = parent + "." + f.Name
+		}
+
+		if f.Name == "" {
+			return nil, fmt.Errorf("schema field name is required")
+		}
+		if names[f.Name] {
+			return nil, fmt.Errorf("schema field %s is declared twice", path)
+		}
+		names[f.Name] = true
+
+		c := field{
+			name:       f.Name,
+			extract:    f.Extract,
+			attr:       f.Attr,
+			list:       f.List,
+			kind:       f.Type,
+			typed:      f.Type != "",
+			dateFormat: f.DateFormat,
+		}
+
+		// Selector kosong hanya boleh di field bersarang, artinya elemen induknya sendiri
+		if f.Selector == "" && f.Path == "" && depth == 0 {
+			return nil, fmt.Errorf("schema field %s requires a selector or path", path)
+		}
+		if f.Selector != "" {
+			sel, err := cascadia.Compile(f.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("schema field %s has invalid selector %q: %v", path, f.Selector, err)
+			}
+			c.selector = sel
+		}
+		if f.Path != "" {
+			steps, err := compilePath(f.Path)
+			if err != nil {
+				return nil, fmt.Errorf("schema field %s has invalid path %q: %v", path, f.Path, err)
+			}
+			c.path = steps
+		}
+
+		if len(f.Fields) > 0 {
+			children, err := compileFields(f.Fields, path, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			c.fields = children
+			compiled = append(compiled, c)
+			continue
+		}
+
+		if c.extract == "" {
+			c.extract = models.ExtractText
+		}
+		switch c.extract {
+		case models.ExtractText, models.ExtractHTML, models.ExtractOuterHTML:
+		case models.ExtractAttr:
+			if c.attr == "" {
+				return nil, fmt.Errorf("schema field %s extracts attr but attr is empty", path)
+			}
+		default:
+			return nil, fmt.Errorf("schema field %s has unknown extract %q", path, f.Extract)
+		}
+
+		if c.kind == "" {
+			c.kind = models.FieldTypeString
+		}
+		switch c.kind {
+		case models.FieldTypeString, models.FieldTypeInt, models.FieldTypeFloat,
+			models.FieldTypeBool, models.FieldTypeDate, models.FieldTypeURL:
+		default:
+			return nil, fmt.Errorf("schema field %s has unknown type %q", path, f.Type)
+		}
+
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// Extract evaluates the schema against doc. pageUrl is the base for url typed
+// fields. Missing or uncoercible values come back as nil so every declared
+// field is present in the result.
+func (e *Extractor) Extract(doc *goquery.Document, pageUrl string) map[string]interface{} {
+	base, _ := url.Parse(pageUrl)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok && base != nil {
+		if resolved, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			base = resolved
+		}
+	}
+	return extractObject(e.fields, doc.Selection, base)
+}
+
+func extractObject(fields []field, scope *goquery.Selection, base *url.URL) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		matched := scope
+		if f.selector != nil {
+			matched = scope.FindMatcher(f.selector)
+		} else if f.path != nil {
+			// Field khusus JSON tidak punya nilai di halaman HTML
+			result[f.name] = nil
+			continue
+		}
+
+		if !f.list {
+			if matched.Length() == 0 {
+				result[f.name] = nil
+				continue
+			}
+			result[f.name] = f.value(matched.First(), base)
+			continue
+		}
+
+		values := make([]interface{}, 0, matched.Length())
+		matched.Each(func(_ int, s *goquery.Selection) {
+			if value := f.value(s, base); value != nil {
+				values = append(values, value)
+			}
+		})
+		result[f.name] = values
+	}
+	return result
+}
+
+func (f field) value(s *goquery.Selection, base *url.URL) interface{} {
+	if len(f.fields) > 0 {
+		return extractObject(f.fields, s, base)
+	}
+
+	var raw string
+	switch f.extract {
+	case models.ExtractHTML:
+		raw, _ = s.Html()
+	case models.ExtractOuterHTML:
+		raw, _ = goquery.OuterHtml(s)
+	case models.ExtractAttr:
+		attr, ok := s.Attr(f.attr)
+		if !ok {
+			return nil
+		}
+		raw = attr
+	default:
+		raw = strings.Join(strings.Fields(s.Text()), " ")
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	return f.coerce(raw, base)
+}
+
+func (f field) coerce(raw string, base *url.URL) interface{} {
+	switch f.kind {
+	case models.FieldTypeInt:
+		n, ok := parseNumber(raw)
+		if !ok {
+			return nil
+		}
+		return int64(n)
+	case models.FieldTypeFloat:
+		n, ok := parseNumber(raw)
+		if !ok {
+			return nil
+		}
+		return n
+	case models.FieldTypeBool:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "1", "on":
+			return true
+		case "false", "no", "0", "off":
+			return false
+		}
+		return nil
+	case models.FieldTypeDate:
+		layouts := dateLayouts
+		if f.dateFormat != "" {
+			layouts = []string{f.dateFormat}
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t.Format(time.RFC3339)
+			}
+		}
+		return nil
+	case models.FieldTypeURL:
+		if base == nil {
+			return raw
+		}
+		resolved, err := base.Parse(raw)
+		if err != nil {
+			return nil
+		}
+		return resolved.String()
+	default:
+		return raw
+	}
+}
+
+// parseNumber menerima angka dengan simbol mata uang dan pemisah ribuan,
+// mis. "Rp 1.250.000", "Rp 1.250" atau "$1,299.99"
+func parseNumber(raw string) (float64, bool) {
+	cleaned := numberCleaner.ReplaceAllString(raw, "")
+	if cleaned == "" {
+		return 0, false
+	}
+
+	lastDot := strings.LastIndex(cleaned, ".")
+	lastComma := strings.LastIndex(cleaned, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Pemisah yang muncul terakhir dianggap desimal
+		if lastComma > lastDot {
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+	case lastComma >= 0:
+		if thousandsSeparated(cleaned, ",") {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		} else {
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		}
+	case lastDot >= 0:
+		if thousandsSeparated(cleaned, ".") {
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+		}
+	}
+
+	n, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// thousandsSeparated reports whether sep, the only separator kind in s,
+// groups thousands rather than marking decimals: it appears more than once,
+// or once with exactly three digits after it and a non-zero integer part, as
+// in "1.250" or "1,250" but not "0.125".
+func thousandsSeparated(s, sep string) bool {
+	if strings.Count(s, sep) > 1 {
+		return true
+	}
+	i := strings.Index(s, sep)
+	intPart := strings.TrimLeft(s[:i], "-0")
+	return len(s)-i-1 == 3 && intPart != ""
+}
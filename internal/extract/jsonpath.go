@@ -0,0 +1,218 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+// pathStep is one segment of a field path: an object key, an array index or
+// a wildcard over every array element or object value.
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// compilePath parses dot notation with bracket indexes, e.g. "$.data.items[*].name",
+// "items[0]" or "meta['og:title']".
+func compilePath(raw string) ([]pathStep, error) {
+	p := strings.TrimSpace(raw)
+	p = strings.TrimPrefix(p, "$")
+	p = strings.TrimPrefix(p, ".")
+
+	steps := []pathStep{}
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			i++
+			if i >= len(p) || p[i] == '.' || p[i] == '[' {
+				return nil, fmt.Errorf("empty key at offset %d", i)
+			}
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket at offset %d", i)
+			}
+			inner := strings.TrimSpace(p[i+1 : i+end])
+			i += end + 1
+
+			switch {
+			case inner == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index %q", inner)
+				}
+				steps = append(steps, pathStep{index: index, isIndex: true})
+			}
+		default:
+			start := i
+			for i < len(p) && p[i] != '.' && p[i] != '[' {
+				i++
+			}
+			key := p[start:i]
+			if key == "*" {
+				steps = append(steps, pathStep{wildcard: true})
+			} else {
+				steps = append(steps, pathStep{key: key})
+			}
+		}
+	}
+	return steps, nil
+}
+
+// resolve returns every value the path reaches from root. Missing keys and
+// out of range indexes simply produce no values.
+func resolve(root interface{}, steps []pathStep) []interface{} {
+	current := []interface{}{root}
+	for _, step := range steps {
+		next := make([]interface{}, 0, len(current))
+		for _, value := range current {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if step.wildcard {
+					// Urutkan key supaya hasil wildcard stabil
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				} else if !step.isIndex {
+					if child, ok := v[step.key]; ok {
+						next = append(next, child)
+					}
+				}
+			case []interface{}:
+				switch {
+				case step.wildcard:
+					next = append(next, v...)
+				case step.isIndex:
+					if step.index < len(v) {
+						next = append(next, v[step.index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// ExtractJSON evaluates the Path fields of the schema against a decoded JSON
+// document. Fields without a path at the top level come back as nil, the same
+// way selector-only fields do for JSON documents.
+func (e *Extractor) ExtractJSON(document interface{}, pageUrl string) map[string]interface{} {
+	base, _ := url.Parse(pageUrl)
+	return extractJSONObject(e.fields, document, base, true)
+}
+
+func extractJSONObject(fields []field, scope interface{}, base *url.URL, top bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		var matched []interface{}
+		switch {
+		case f.path != nil:
+			matched = resolve(scope, f.path)
+		case top:
+			result[f.name] = nil
+			continue
+		default:
+			matched = []interface{}{scope}
+		}
+
+		if !f.list {
+			if len(matched) == 0 {
+				result[f.name] = nil
+				continue
+			}
+			result[f.name] = f.jsonValue(matched[0], base)
+			continue
+		}
+
+		values := make([]interface{}, 0, len(matched))
+		for _, m := range matched {
+			// Path yang berakhir di array dengan list=true berarti isi array-nya
+			if items, ok := m.([]interface{}); ok && len(matched) == 1 {
+				for _, item := range items {
+					if value := f.jsonValue(item, base); value != nil {
+						values = append(values, value)
+					}
+				}
+				continue
+			}
+			if value := f.jsonValue(m, base); value != nil {
+				values = append(values, value)
+			}
+		}
+		result[f.name] = values
+	}
+	return result
+}
+
+// jsonValue keeps native JSON values where the declared type allows it and
+// goes through the same coercion as HTML text otherwise.
+func (f field) jsonValue(value interface{}, base *url.URL) interface{} {
+	if len(f.fields) > 0 {
+		return extractJSONObject(f.fields, value, base, false)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		return f.coerce(strings.TrimSpace(v), base)
+	case float64:
+		switch f.kind {
+		case models.FieldTypeInt:
+			return int64(math.Trunc(v))
+		case models.FieldTypeFloat:
+			return v
+		case models.FieldTypeBool:
+			return v != 0
+		case models.FieldTypeString:
+			if f.typed {
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			return v
+		}
+		return f.coerce(strconv.FormatFloat(v, 'f', -1, 64), base)
+	case bool:
+		switch f.kind {
+		case models.FieldTypeBool:
+			return v
+		case models.FieldTypeString:
+			if f.typed {
+				return strconv.FormatBool(v)
+			}
+			return v
+		}
+		return nil
+	default:
+		// Object atau array tanpa Fields dikembalikan apa adanya untuk type string default
+		if f.kind == models.FieldTypeString && !f.typed {
+			return v
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,33 @@
+package extract
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want float64
+	}{
+		{"Rp 1.250", 1250},
+		{"Rp 1.250.000", 1250000},
+		{"Rp. 15.000", 15000},
+		{"1,250", 1250},
+		{"$1,299.99", 1299.99},
+		{"1.299,99", 1299.99},
+		{"12,5", 12.5},
+		{"4.5", 4.5},
+		{"0.125", 0.125},
+		{"0,125", 0.125},
+		{"-1.500", -1500},
+		{"1.2345", 1.2345},
+	}
+	for _, c := range cases {
+		got, ok := parseNumber(c.raw)
+		if !ok || got != c.want {
+			t.Errorf("parseNumber(%q) = %v, %v; want %v", c.raw, got, ok, c.want)
+		}
+	}
+
+	if _, ok := parseNumber("gratis"); ok {
+		t.Error(`parseNumber("gratis") succeeded`)
+	}
+}
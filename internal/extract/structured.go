@@ -0,0 +1,279 @@
+package extract
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// openGraphPrefixes are the meta property namespaces published alongside og:
+var openGraphPrefixes = []string{"og:", "article:", "book:", "profile:", "product:", "music:", "video:", "fb:"}
+
+// Structured collects the metadata a page publishes about itself: JSON-LD
+// blocks, schema.org Microdata, RDFa Lite, OpenGraph and Twitter Card tags.
+// Formats the page doesn't use are left out of the result.
+func Structured(doc *goquery.Document, pageUrl string) models.JSONB {
+	base, _ := url.Parse(pageUrl)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok && base != nil {
+		if resolved, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			base = resolved
+		}
+	}
+
+	data := make(models.JSONB)
+	if items := jsonLD(doc); len(items) > 0 {
+		data["json_ld"] = items
+	}
+	if items := microdata(doc, base); len(items) > 0 {
+		data["microdata"] = items
+	}
+	if items := rdfa(doc, base); len(items) > 0 {
+		data["rdfa"] = items
+	}
+	if tags := metaTags(doc, "property", openGraphPrefixes); len(tags) > 0 {
+		data["opengraph"] = tags
+	}
+	if tags := metaTags(doc, "name", []string{"twitter:"}); len(tags) > 0 {
+		data["twitter"] = tags
+	}
+	return data
+}
+
+func jsonLD(doc *goquery.Document) []interface{} {
+	items := make([]interface{}, 0)
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		raw := strings.TrimSpace(s.Text())
+		// Beberapa CMS masih membungkus isi script dengan komentar atau CDATA
+		raw = strings.TrimSuffix(strings.TrimPrefix(raw, "<!--"), "-->")
+		raw = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(raw), "<![CDATA["), "]]>")
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return
+		}
+		if list, ok := value.([]interface{}); ok {
+			items = append(items, list...)
+			return
+		}
+		items = append(items, value)
+	})
+	return items
+}
+
+func microdata(doc *goquery.Document, base *url.URL) []interface{} {
+	items := make([]interface{}, 0)
+	doc.Find("[itemscope]").Each(func(_ int, s *goquery.Selection) {
+		// Item yang punya itemprop adalah nilai dari item lain
+		if _, nested := s.Attr("itemprop"); nested {
+			return
+		}
+		items = append(items, microdataItem(doc, s, base, 0))
+	})
+	return items
+}
+
+func microdataItem(doc *goquery.Document, item *goquery.Selection, base *url.URL, depth int) map[string]interface{} {
+	result := make(map[string]interface{})
+	if itemType := strings.Fields(item.AttrOr("itemtype", "")); len(itemType) > 0 {
+		result["type"] = itemType
+	}
+	if id := strings.TrimSpace(item.AttrOr("itemid", "")); id != "" {
+		result["id"] = id
+	}
+
+	properties := make(map[string][]interface{})
+	roots := []*goquery.Selection{item.Children()}
+	for _, ref := range strings.Fields(item.AttrOr("itemref", "")) {
+		if referenced := doc.Find("#" + cssEscape(ref)); referenced.Length() > 0 {
+			roots = append(roots, referenced.First())
+		}
+	}
+	for _, root := range roots {
+		root.Each(func(_ int, s *goquery.Selection) {
+			collectProperties(s, "itemprop", "itemscope", properties, func(prop *goquery.Selection) interface{} {
+				if _, scoped := prop.Attr("itemscope"); scoped {
+					if depth >= maxNesting {
+						return nil
+					}
+					return microdataItem(doc, prop, base, depth+1)
+				}
+				return propertyValue(prop, base)
+			})
+		})
+	}
+
+	if len(properties) > 0 {
+		result["properties"] = properties
+	}
+	return result
+}
+
+func rdfa(doc *goquery.Document, base *url.URL) []interface{} {
+	items := make([]interface{}, 0)
+	doc.Find("[typeof]").Each(func(_ int, s *goquery.Selection) {
+		if _, nested := s.Attr("property"); nested {
+			return
+		}
+		items = append(items, rdfaItem(s, base, 0))
+	})
+	return items
+}
+
+func rdfaItem(item *goquery.Selection, base *url.URL, depth int) map[string]interface{} {
+	result := make(map[string]interface{})
+	if itemType := strings.Fields(item.AttrOr("typeof", "")); len(itemType) > 0 {
+		result["type"] = itemType
+	}
+	// vocab dan prefix diwarisi dari elemen terdekat yang mendeklarasikannya
+	if vocab := closestAttr(item, "vocab"); vocab != "" {
+		result["vocab"] = vocab
+	}
+	if prefix := closestAttr(item, "prefix"); prefix != "" {
+		result["prefix"] = prefix
+	}
+	if resource := item.AttrOr("resource", ""); resource != "" {
+		result["id"] = resolveURL(base, resource)
+	}
+
+	properties := make(map[string][]interface{})
+	item.Children().Each(func(_ int, s *goquery.Selection) {
+		collectProperties(s, "property", "typeof", properties, func(prop *goquery.Selection) interface{} {
+			if _, typed := prop.Attr("typeof"); typed {
+				if depth >= maxNesting {
+					return nil
+				}
+				return rdfaItem(prop, base, depth+1)
+			}
+			if resource, ok := prop.Attr("resource"); ok {
+				return resolveURL(base, resource)
+			}
+			return propertyValue(prop, base)
+		})
+	})
+
+	if len(properties) > 0 {
+		result["properties"] = properties
+	}
+	return result
+}
+
+// collectProperties walks s and its descendants, recording every element that
+// carries propAttr. It does not descend into elements that open a new item
+// (scopeAttr), their properties belong to that item.
+func collectProperties(s *goquery.Selection, propAttr, scopeAttr string, properties map[string][]interface{}, value func(*goquery.Selection) interface{}) {
+	if names, ok := s.Attr(propAttr); ok {
+		if v := value(s); v != nil {
+			for _, name := range strings.Fields(names) {
+				properties[name] = append(properties[name], v)
+			}
+		}
+	}
+	if _, scoped := s.Attr(scopeAttr); scoped {
+		return
+	}
+	s.Children().Each(func(_ int, child *goquery.Selection) {
+		collectProperties(child, propAttr, scopeAttr, properties, value)
+	})
+}
+
+// propertyValue mengikuti aturan nilai properti Microdata, juga dipakai RDFa
+// untuk elemen tanpa resource
+func propertyValue(s *goquery.Selection, base *url.URL) interface{} {
+	if content, ok := s.Attr("content"); ok {
+		return strings.TrimSpace(content)
+	}
+
+	switch goquery.NodeName(s) {
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return resolveURL(base, s.AttrOr("src", ""))
+	case "a", "area", "link":
+		return resolveURL(base, s.AttrOr("href", ""))
+	case "object":
+		return resolveURL(base, s.AttrOr("data", ""))
+	case "data", "meter":
+		return strings.TrimSpace(s.AttrOr("value", ""))
+	case "time":
+		if datetime, ok := s.Attr("datetime"); ok {
+			return strings.TrimSpace(datetime)
+		}
+	}
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
+
+// metaTags returns meta tags whose attr starts with one of prefixes. Tags
+// repeated on the page, such as og:image, become a list.
+func metaTags(doc *goquery.Document, attr string, prefixes []string) map[string]interface{} {
+	tags := make(map[string]interface{})
+	doc.Find("meta[" + attr + "]").Each(func(_ int, s *goquery.Selection) {
+		key := strings.ToLower(strings.TrimSpace(s.AttrOr(attr, "")))
+		if !hasAnyPrefix(key, prefixes) {
+			return
+		}
+		content, ok := s.Attr("content")
+		if !ok {
+			return
+		}
+		content = strings.TrimSpace(content)
+
+		switch existing := tags[key].(type) {
+		case nil:
+			tags[key] = content
+		case string:
+			tags[key] = []string{existing, content}
+		case []string:
+			tags[key] = append(existing, content)
+		}
+	})
+	return tags
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func closestAttr(s *goquery.Selection, attr string) string {
+	for node := s; node.Length() > 0; node = node.Parent() {
+		if value, ok := node.Attr(attr); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func resolveURL(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if base == nil || raw == "" {
+		return raw
+	}
+	resolved, err := base.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return resolved.String()
+}
+
+// cssEscape keeps itemref ids usable in an #id selector.
+func cssEscape(id string) string {
+	var b strings.Builder
+	for _, r := range id {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r > 0x7f {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteRune('\\')
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,321 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	TypeRSS      = "rss"
+	TypeAtom     = "atom"
+	TypeRDF      = "rdf"
+	TypeJSONFeed = "json_feed"
+)
+
+// Feed is the common shape of RSS, Atom and JSON Feed documents.
+type Feed struct {
+	Type  string
+	Title string
+	Items []Item
+}
+
+// Item is one feed entry. GUID falls back to the link when the feed has no
+// stable id, so it can always be used for dedup.
+type Item struct {
+	GUID      string
+	Link      string
+	Title     string
+	Author    string
+	Published *time.Time
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"Mon, 02 Jan 06 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Parse detects the feed format from the document itself. Relative item
+// links are resolved against feedUrl.
+func Parse(body []byte, feedUrl string) (*Feed, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty feed")
+	}
+
+	var (
+		parsed *Feed
+		err    error
+	)
+	if trimmed[0] == '{' {
+		parsed, err = parseJSONFeed(trimmed)
+	} else {
+		parsed, err = parseXMLFeed(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base, _ := url.Parse(feedUrl)
+	items := parsed.Items[:0]
+	for _, item := range parsed.Items {
+		item.Link = strings.TrimSpace(item.Link)
+		if base != nil && item.Link != "" {
+			if resolved, err := base.Parse(item.Link); err == nil {
+				item.Link = resolved.String()
+			}
+		}
+		item.GUID = strings.TrimSpace(item.GUID)
+		if item.GUID == "" {
+			item.GUID = item.Link
+		}
+		if item.GUID == "" {
+			continue
+		}
+		item.Title = strings.Join(strings.Fields(item.Title), " ")
+		item.Author = strings.TrimSpace(item.Author)
+		items = append(items, item)
+	}
+	parsed.Items = items
+	parsed.Title = strings.Join(strings.Fields(parsed.Title), " ")
+	return parsed, nil
+}
+
+type xmlFeed struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 (RDF) menaruh item sejajar dengan channel
+	RDFItems []rssItem `xml:"item"`
+	// Atom
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title string `xml:"title"`
+	// atom:link di dalam item juga bernama link, isinya ada di atribut href
+	Links []struct {
+		Text string `xml:",chardata"`
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	// dc:date dan dc:creator dipakai RSS 1.0 dan banyak feed RSS 2.0
+	DCDate    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author    string `xml:"author"`
+	DCCreator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	About     string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+func parseXMLFeed(body []byte) (*Feed, error) {
+	var doc xmlFeed
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid feed xml: %w", err)
+	}
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		return &Feed{Type: TypeRSS, Title: doc.Channel.Title, Items: rssItems(doc.Channel.Items)}, nil
+	case "rdf":
+		return &Feed{Type: TypeRDF, Title: doc.Channel.Title, Items: rssItems(doc.RDFItems)}, nil
+	case "feed":
+		feed := &Feed{Type: TypeAtom, Title: doc.Title}
+		for _, entry := range doc.Entries {
+			item := Item{
+				GUID:      entry.ID,
+				Title:     entry.Title,
+				Link:      atomAlternate(entry.Links),
+				Published: parseDate(entry.Published),
+			}
+			if item.Published == nil {
+				item.Published = parseDate(entry.Updated)
+			}
+			names := make([]string, 0, len(entry.Authors))
+			for _, author := range entry.Authors {
+				if name := strings.TrimSpace(author.Name); name != "" {
+					names = append(names, name)
+				}
+			}
+			item.Author = strings.Join(names, ", ")
+			feed.Items = append(feed.Items, item)
+		}
+		return feed, nil
+	default:
+		return nil, fmt.Errorf("unknown feed root element %q", doc.XMLName.Local)
+	}
+}
+
+func rssItems(raw []rssItem) []Item {
+	items := make([]Item, 0, len(raw))
+	for _, entry := range raw {
+		item := Item{
+			GUID:      entry.GUID,
+			Title:     entry.Title,
+			Author:    entry.DCCreator,
+			Published: parseDate(entry.PubDate),
+		}
+		for _, link := range entry.Links {
+			if text := strings.TrimSpace(link.Text); text != "" {
+				item.Link = text
+				break
+			}
+		}
+		if item.Link == "" && len(entry.Links) > 0 {
+			item.Link = entry.Links[0].Href
+		}
+		if item.Link == "" {
+			item.Link = entry.About
+		}
+		if item.Author == "" {
+			item.Author = entry.Author
+		}
+		if item.Published == nil {
+			item.Published = parseDate(entry.DCDate)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// atomAlternate memilih link rel=alternate (default Atom), HTML lebih diutamakan
+func atomAlternate(links []atomLink) string {
+	best := ""
+	for _, link := range links {
+		if link.Rel != "" && link.Rel != "alternate" {
+			continue
+		}
+		if link.Type == "" || strings.Contains(link.Type, "html") {
+			return link.Href
+		}
+		if best == "" {
+			best = link.Href
+		}
+	}
+	return best
+}
+
+type jsonFeed struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Items   []struct {
+		ID            json.RawMessage `json:"id"`
+		URL           string          `json:"url"`
+		ExternalURL   string          `json:"external_url"`
+		Title         string          `json:"title"`
+		DatePublished string          `json:"date_published"`
+		DateModified  string          `json:"date_modified"`
+		Author        *jsonAuthor     `json:"author"`
+		Authors       []jsonAuthor    `json:"authors"`
+	} `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+func parseJSONFeed(body []byte) (*Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid json feed: %w", err)
+	}
+	if !strings.Contains(doc.Version, "jsonfeed.org") {
+		return nil, fmt.Errorf("not a json feed")
+	}
+
+	feed := &Feed{Type: TypeJSONFeed, Title: doc.Title}
+	for _, entry := range doc.Items {
+		item := Item{
+			GUID:      jsonID(entry.ID),
+			Title:     entry.Title,
+			Link:      entry.URL,
+			Published: parseDate(entry.DatePublished),
+		}
+		if item.Link == "" {
+			item.Link = entry.ExternalURL
+		}
+		if item.Published == nil {
+			item.Published = parseDate(entry.DateModified)
+		}
+
+		authors := entry.Authors
+		if entry.Author != nil {
+			authors = append(authors, *entry.Author)
+		}
+		names := make([]string, 0, len(authors))
+		for _, author := range authors {
+			if name := strings.TrimSpace(author.Name); name != "" {
+				names = append(names, name)
+			}
+		}
+		item.Author = strings.Join(names, ", ")
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// jsonID menerima id berupa string maupun angka, spesifikasi meminta string
+// tapi banyak generator menulis angka
+func jsonID(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	return strings.Trim(string(raw), `" `)
+}
+
+func parseDate(raw string) *time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRSS(t *testing.T) {
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>  Berita
+      Terbaru </title>
+    <item>
+      <title>Text link</title>
+      <link> /posts/1 </link>
+      <guid isPermaLink="false">post-1</guid>
+      <pubDate>Sun, 01 Mar 2026 10:00:00 +0700</pubDate>
+      <author>ed@a.com</author>
+    </item>
+    <item>
+      <title>Atom link only</title>
+      <atom:link href="https://a.com/posts/2" rel="alternate"/>
+      <dc:creator>Budi</dc:creator>
+      <dc:date>2026-03-02T08:00:00Z</dc:date>
+    </item>
+    <item>
+      <title>Text link wins</title>
+      <atom:link href="https://a.com/atom/3"/>
+      <link>https://a.com/posts/3</link>
+    </item>
+    <item>
+      <title>No link and no guid</title>
+    </item>
+  </channel>
+</rss>`
+
+	parsed, err := Parse([]byte(body), "https://a.com/feed.xml")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.Type != TypeRSS || parsed.Title != "Berita Terbaru" {
+		t.Errorf("feed = %s %q", parsed.Type, parsed.Title)
+	}
+	if len(parsed.Items) != 3 {
+		t.Fatalf("items = %+v, want 3", parsed.Items)
+	}
+
+	first := parsed.Items[0]
+	if first.GUID != "post-1" || first.Link != "https://a.com/posts/1" || first.Author != "ed@a.com" {
+		t.Errorf("item 1 = %+v", first)
+	}
+	if first.Published == nil || !first.Published.Equal(time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("item 1 published = %v", first.Published)
+	}
+
+	// Tanpa guid, link dari atom:link dipakai sebagai GUID
+	second := parsed.Items[1]
+	if second.Link != "https://a.com/posts/2" || second.GUID != second.Link || second.Author != "Budi" {
+		t.Errorf("item 2 = %+v", second)
+	}
+	if second.Published == nil || !second.Published.Equal(time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("item 2 published = %v", second.Published)
+	}
+
+	if third := parsed.Items[2]; third.Link != "https://a.com/posts/3" {
+		t.Errorf("item 3 link = %q, want the text link", third.Link)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://a.com/">
+    <title>RDF feed</title>
+  </channel>
+  <item rdf:about="https://a.com/r/1">
+    <title>With link</title>
+    <link>https://a.com/r/1.html</link>
+  </item>
+  <item rdf:about="https://a.com/r/2">
+    <title>About only</title>
+    <dc:date>2026-03-01</dc:date>
+  </item>
+</rdf:RDF>`
+
+	parsed, err := Parse([]byte(body), "https://a.com/index.rdf")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.Type != TypeRDF || parsed.Title != "RDF feed" {
+		t.Errorf("feed = %s %q", parsed.Type, parsed.Title)
+	}
+	if len(parsed.Items) != 2 {
+		t.Fatalf("items = %+v, want 2", parsed.Items)
+	}
+	if parsed.Items[0].Link != "https://a.com/r/1.html" {
+		t.Errorf("item 1 link = %q", parsed.Items[0].Link)
+	}
+	// rdf:about jadi link kalau item tidak punya <link>
+	second := parsed.Items[1]
+	if second.Link != "https://a.com/r/2" || second.GUID != "https://a.com/r/2" || second.Published == nil {
+		t.Errorf("item 2 = %+v", second)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	body := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom feed</title>
+  <entry>
+    <id>tag:a.com,2026:1</id>
+    <title>Alternate</title>
+    <link rel="self" href="https://a.com/entries/1.atom"/>
+    <link rel="alternate" type="application/pdf" href="https://a.com/entries/1.pdf"/>
+    <link href="https://a.com/entries/1"/>
+    <updated>2026-03-01T10:00:00Z</updated>
+    <author><name>Ani</name></author>
+    <author><name> Budi </name></author>
+  </entry>
+  <entry>
+    <title>Only other types</title>
+    <link rel="alternate" type="application/pdf" href="entries/2.pdf"/>
+    <published>2026-03-02T10:00:00+07:00</published>
+  </entry>
+</feed>`
+
+	parsed, err := Parse([]byte(body), "https://a.com/feed.atom")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.Type != TypeAtom || len(parsed.Items) != 2 {
+		t.Fatalf("feed = %+v", parsed)
+	}
+
+	first := parsed.Items[0]
+	if first.GUID != "tag:a.com,2026:1" || first.Link != "https://a.com/entries/1" || first.Author != "Ani, Budi" {
+		t.Errorf("item 1 = %+v", first)
+	}
+	if first.Published == nil || !first.Published.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("item 1 published = %v, want updated", first.Published)
+	}
+
+	second := parsed.Items[1]
+	if second.Link != "https://a.com/entries/2.pdf" || second.GUID != second.Link {
+		t.Errorf("item 2 = %+v", second)
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	body := `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON feed",
+  "items": [
+    {"id": 123, "url": "https://a.com/j/1", "title": "Numeric id", "date_published": "2026-03-01T10:00:00Z", "authors": [{"name": "Ani"}]},
+    {"id": "abc", "external_url": "https://b.com/x", "title": "External", "date_modified": "2026-03-02T10:00:00Z", "author": {"name": "Budi"}},
+    {"id": 1.5e3, "url": "/j/3"},
+    {"url": "https://a.com/j/4"},
+    {"id": null, "title": "Nothing to link"}
+  ]
+}`
+
+	parsed, err := Parse([]byte(body), "https://a.com/feed.json")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.Type != TypeJSONFeed || parsed.Title != "JSON feed" {
+		t.Errorf("feed = %s %q", parsed.Type, parsed.Title)
+	}
+
+	want := []struct{ guid, link, author string }{
+		{"123", "https://a.com/j/1", "Ani"},
+		{"abc", "https://b.com/x", "Budi"},
+		{"1.5e3", "https://a.com/j/3", ""},
+		{"https://a.com/j/4", "https://a.com/j/4", ""},
+	}
+	if len(parsed.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d", parsed.Items, len(want))
+	}
+	for i, w := range want {
+		item := parsed.Items[i]
+		if item.GUID != w.guid || item.Link != w.link || item.Author != w.author {
+			t.Errorf("item %d = %+v, want %+v", i, item, w)
+		}
+	}
+	if parsed.Items[1].Published == nil {
+		t.Error("date_modified not used as published")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, body := range map[string]string{
+		"empty":          "  ",
+		"html":           "<html><body>no feed</body></html>",
+		"other json":     `{"items": []}`,
+		"broken json":    `{"version": "https://jsonfeed.org/version/1"`,
+		"broken xml":     "<rss><channel><item>",
+		"not xml at all": "hello",
+	} {
+		if _, err := Parse([]byte(body), "https://a.com/feed"); err == nil {
+			t.Errorf("%s: Parse accepted %q", name, body)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, raw := range []string{
+		"Sun, 01 Mar 2026 10:00:00 +0700",
+		"Sun, 01 Mar 2026 10:00:00 GMT",
+		"Sun, 1 Mar 2026 10:00:00 +0700",
+		"01 Mar 26 10:00 +0700",
+		"2026-03-01T10:00:00+07:00",
+		"2026-03-01T10:00:00",
+		"2026-03-01 10:00:00",
+		" 2026-03-01 ",
+	} {
+		if parseDate(raw) == nil {
+			t.Errorf("parseDate(%q) = nil", raw)
+		}
+	}
+	for _, raw := range []string{"", "kemarin", "03/01/2026"} {
+		if got := parseDate(raw); got != nil {
+			t.Errorf("parseDate(%q) = %s, want nil", raw, got)
+		}
+	}
+}
@@ -1,20 +1,52 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/google/uuid"
 )
 
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff doubles BaseDelay for every attempt, capped at MaxDelay, plus up to
+// 20% jitter so retries of one failing host don't arrive together.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
 type ConsumerCrawlerHandler struct {
-	crawlHandler *CrawlHandler
+	crawlHandler   *CrawlHandler
+	producer       *queue.Producer
+	deadLetterRepo repository.DeadLetterRepository
+	retryPolicy    RetryPolicy
+	kafkaTopic     string
 }
 
-func NewConsumerCrawlerHandler(crawlHandler *CrawlHandler) (*ConsumerCrawlerHandler, error) {
+func NewConsumerCrawlerHandler(crawlHandler *CrawlHandler, producer *queue.Producer, deadLetterRepo repository.DeadLetterRepository, retryPolicy RetryPolicy, topic string) (*ConsumerCrawlerHandler, error) {
 	return &ConsumerCrawlerHandler{
-		crawlHandler: crawlHandler,
+		crawlHandler:   crawlHandler,
+		producer:       producer,
+		deadLetterRepo: deadLetterRepo,
+		retryPolicy:    retryPolicy,
+		kafkaTopic:     topic,
 	}, nil
 }
 
@@ -34,12 +66,134 @@ func (c *ConsumerCrawlerHandler) ConsumeClaim(session sarama.ConsumerGroupSessio
 		err := json.Unmarshal(msg.Value, &job)
 		if err != nil {
 			log.Printf("Error when parshing JSON : %v", err)
+			c.deadLetter(msg.Topic, nil, string(msg.Value), fmt.Sprintf("failed to decode message: %v", err), 0)
 			session.MarkMessage(msg, "")
 			continue
 		}
 
-		c.crawlHandler.ProcessCrawl(job)
+		// Pesan dari topic retry ditahan sampai backoff-nya habis
+		if job.NotBefore != nil {
+			if err := waitUntil(session.Context(), *job.NotBefore); err != nil {
+				return nil
+			}
+		}
+
+		err = c.crawlHandler.ProcessCrawl(session.Context(), job)
+		if err != nil && session.Context().Err() != nil {
+			// Rebalance atau shutdown, pesan tidak di-mark supaya dikirim ulang
+			return nil
+		}
+		if err != nil {
+			c.handleFailure(msg.Topic, job, err)
+		}
+
 		session.MarkMessage(msg, "")
 	}
 	return nil
 }
+
+func (c *ConsumerCrawlerHandler) handleFailure(sourceTopic string, job models.CrawlJob, err error) {
+	var crawlErr *CrawlError
+	if !errors.As(err, &crawlErr) {
+		crawlErr = retryableError("unexpected error", err)
+	}
+
+	nextAttempt := job.Attempt + 1
+	if crawlErr.Retryable && nextAttempt < c.retryPolicy.MaxAttempts {
+		err := c.scheduleRetry(job, crawlErr, nextAttempt)
+		if err == nil {
+			return
+		}
+		log.Printf("[RETRY_ERROR] failed to schedule retry for job %s: %v", job.ID, err)
+	}
+
+	log.Printf("[DEAD_LETTER] job %s failed after %d attempt(s): %v", job.ID, nextAttempt, crawlErr)
+	c.crawlHandler.failJob(job, crawlErr)
+
+	payload, _ := json.Marshal(job)
+	c.deadLetter(sourceTopic, &job, string(payload), crawlErr.Error(), nextAttempt)
+}
+
+func (c *ConsumerCrawlerHandler) scheduleRetry(job models.CrawlJob, crawlErr *CrawlError, attempt int) error {
+	notBefore := time.Now().Add(c.retryPolicy.Backoff(attempt))
+	job.Attempt = attempt
+	job.NotBefore = &notBefore
+
+	transition := repository.JobTransition{
+		FailureReason: crawlErr.Error(),
+		HTTPStatus:    crawlErr.HTTPStatus,
+		Attempts:      attempt,
+	}
+	if err := c.crawlHandler.jobRepo.TransitionJob(job.ID, models.JobStatusQueued, transition); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	retryTopic := queue.RetryTopic(c.kafkaTopic, attempt)
+	if _, _, err := c.producer.PublishMessage(retryTopic, job.ID, string(payload)); err != nil {
+		return err
+	}
+
+	log.Printf("[RETRY] job %s scheduled to %s at %v (attempt %d)", job.ID, retryTopic, notBefore, attempt)
+	return nil
+}
+
+type deadLetterMessage struct {
+	ID          string `json:"id"`
+	SourceTopic string `json:"source_topic"`
+	Reason      string `json:"reason"`
+	Attempts    int    `json:"attempts"`
+	Payload     string `json:"payload"`
+}
+
+func (c *ConsumerCrawlerHandler) deadLetter(sourceTopic string, job *models.CrawlJob, payload, reason string, attempts int) {
+	deadLetter := models.DeadLetter{
+		ID:          uuid.New().String(),
+		SourceTopic: sourceTopic,
+		Payload:     payload,
+		Reason:      reason,
+		Attempts:    attempts,
+	}
+	if job != nil {
+		deadLetter.JobID = &job.ID
+		if job.SessionId != "" {
+			deadLetter.SessionID = &job.SessionId
+		}
+	}
+
+	if err := c.deadLetterRepo.SaveDeadLetter(&deadLetter); err != nil {
+		log.Printf("[DEAD_LETTER_ERROR] failed to save dead letter: %v", err)
+	}
+
+	message, _ := json.Marshal(deadLetterMessage{
+		ID:          deadLetter.ID,
+		SourceTopic: sourceTopic,
+		Reason:      reason,
+		Attempts:    attempts,
+		Payload:     payload,
+	})
+	if _, _, err := c.producer.PublishMessage(queue.DeadLetterTopic, deadLetter.ID, string(message)); err != nil {
+		log.Printf("[DEAD_LETTER_ERROR] failed to publish dead letter: %v", err)
+	}
+}
+
+func waitUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MrBista/The-Crawler/internal/canonical"
+	"github.com/MrBista/The-Crawler/internal/dedup"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type CrawlApiHandler struct {
+	repo          repository.CrawlRepository
+	linkRepo      repository.LinkRepository
+	jobRepo       repository.JobRepository
+	sessionRepo   repository.SessionRepository
+	visited       dedup.VisitedSet
+	canonicalizer *canonical.Canonicalizer
+	producer      *queue.Producer
+	kafkaTopic    string
+}
+
+func NewCrawlApiHandler(repo repository.CrawlRepository, linkRepo repository.LinkRepository, jobRepo repository.JobRepository, sessionRepo repository.SessionRepository, visited dedup.VisitedSet, canonicalizer *canonical.Canonicalizer, producer *queue.Producer, topic string) *CrawlApiHandler {
+	return &CrawlApiHandler{
+		repo:          repo,
+		linkRepo:      linkRepo,
+		jobRepo:       jobRepo,
+		sessionRepo:   sessionRepo,
+		visited:       visited,
+		canonicalizer: canonicalizer,
+		producer:      producer,
+		kafkaTopic:    topic,
+	}
+}
+
+func (h *CrawlApiHandler) SubmitCrawl(c *fiber.Ctx) error {
+	var reqBody models.CrawlJob
+
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to parse body",
+		})
+	}
+
+	if reqBody.Url == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "url body is required",
+		})
+	}
+
+	canonicalUrl, err := h.canonicalizer.Canonicalize(reqBody.Url)
+	if err != nil || !strings.HasPrefix(canonicalUrl, "http") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "url must be an absolute http or https url",
+		})
+	}
+
+	if err := validateCrawlRequest(canonicalUrl, reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": err.Error(),
+		})
+	}
+
+	jobId := uuid.New().String()
+	job := newRootJob(jobId, canonicalUrl, reqBody)
+	session := newRootSession(job, reqBody.Budget)
+
+	if err := startRootJob(h.sessionRepo, h.jobRepo, h.visited, h.producer, h.kafkaTopic, job, session); err != nil {
+		log.Printf("[API_ERROR] failed to start crawl %s: %v", jobId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to start crawl job",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{
+			"job_id":     jobId,
+			"session_id": jobId,
+			"status":     models.JobStatusQueued,
+			"Message":    "message stored in brokers",
+		},
+	})
+}
+
+func (h *CrawlApiHandler) GetCrawl(c *fiber.Ctx) error {
+	jobId := c.Params("id")
+
+	job, err := h.jobRepo.FindJobByID(jobId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "crawl job not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find job %s: %v", jobId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl job",
+		})
+	}
+
+	page, err := h.repo.FindPageByID(jobId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[API_ERROR] failed to find page %s: %v", jobId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl job",
+		})
+	}
+
+	// Halaman hanya ada kalau worker berhasil fetch
+	pages := []models.CrawlPage{}
+	if page != nil {
+		pages = append(pages, *page)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{
+			"job_id": jobId,
+			"status": job.Status,
+			"job":    job,
+			"pages":  pages,
+		},
+	})
+}
+
+func (h *CrawlApiHandler) GetCrawlPages(c *fiber.Ctx) error {
+	jobId := c.Params("id")
+	page, limit := parsePagination(c)
+
+	pages, total, err := h.repo.FindDescendantPages(jobId, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find descendant pages %s: %v", jobId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl pages",
+		})
+	}
+
+	if pages == nil {
+		pages = []models.CrawlPage{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": pages,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *CrawlApiHandler) GetOutlinks(c *fiber.Ctx) error {
+	return h.getLinks(c, func(page *models.CrawlPage, limit, offset int) ([]models.PageLink, int64, error) {
+		return h.linkRepo.FindOutlinks(page.ID, limit, offset)
+	})
+}
+
+func (h *CrawlApiHandler) GetInlinks(c *fiber.Ctx) error {
+	return h.getLinks(c, func(page *models.CrawlPage, limit, offset int) ([]models.PageLink, int64, error) {
+		targetUrls := []string{page.URL}
+		for _, alias := range []string{page.CanonicalURL, page.FinalURL} {
+			if alias != "" && alias != page.URL {
+				targetUrls = append(targetUrls, alias)
+			}
+		}
+		return h.linkRepo.FindInlinks(page.SessionID, targetUrls, limit, offset)
+	})
+}
+
+func (h *CrawlApiHandler) getLinks(c *fiber.Ctx, find func(page *models.CrawlPage, limit, offset int) ([]models.PageLink, int64, error)) error {
+	pageId := c.Params("id")
+	page, limit := parsePagination(c)
+
+	crawlPage, err := h.repo.FindPageByID(pageId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "crawl page not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find page %s: %v", pageId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl page",
+		})
+	}
+
+	links, total, err := find(crawlPage, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find links of page %s: %v", pageId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get page links",
+		})
+	}
+
+	if links == nil {
+		links = []models.PageLink{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": links,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *CrawlApiHandler) CancelCrawl(c *fiber.Ctx) error {
+	return h.changeSessionStatus(c, models.SessionStatusCancelled)
+}
+
+func (h *CrawlApiHandler) PauseCrawl(c *fiber.Ctx) error {
+	return h.changeSessionStatus(c, models.SessionStatusPaused)
+}
+
+func (h *CrawlApiHandler) ResumeCrawl(c *fiber.Ctx) error {
+	return h.changeSessionStatus(c, models.SessionStatusRunning)
+}
+
+// changeSessionStatus applies cancel, pause or resume to the session the job
+// belongs to, then cancels or republishes the jobs parked while paused.
+func (h *CrawlApiHandler) changeSessionStatus(c *fiber.Ctx, status string) error {
+	jobId := c.Params("id")
+
+	job, err := h.jobRepo.FindJobByID(jobId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "crawl job not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find job %s: %v", jobId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl job",
+		})
+	}
+
+	sessionId := job.SessionID
+	if sessionId == "" {
+		sessionId = job.ID
+	}
+
+	err = h.sessionRepo.TransitionSession(sessionId, status)
+	if errors.Is(err, repository.ErrInvalidTransition) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"data":    nil,
+			"message": fmt.Sprintf("crawl session can not be moved to %s", status),
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to move session %s to %s: %v", sessionId, status, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to update crawl session",
+		})
+	}
+
+	if status != models.SessionStatusPaused {
+		parkedJobs, err := h.sessionRepo.TakeParkedJobs(sessionId)
+		if err != nil {
+			log.Printf("[API_ERROR] failed to take parked jobs of session %s: %v", sessionId, err)
+		}
+		for _, parked := range parkedJobs {
+			if status == models.SessionStatusCancelled {
+				err = cancelParkedJob(h.jobRepo, h.sessionRepo, parked)
+			} else {
+				err = requeueParkedJob(h.jobRepo, h.producer, h.kafkaTopic, parked)
+			}
+			if err != nil {
+				log.Printf("[API_ERROR] failed to release parked job %s: %v", parked.JobID, err)
+			}
+		}
+	}
+
+	session, err := h.sessionRepo.FindSessionByID(sessionId)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find session %s: %v", sessionId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl session",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": session,
+	})
+}
+
+func parsePagination(c *fiber.Ctx) (int, int) {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", defaultPageLimit)
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return page, limit
+}
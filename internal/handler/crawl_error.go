@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// CrawlError is returned by ProcessCrawl when a job fails. Retryable errors
+// are re-published with backoff; permanent ones go straight to dead letter.
+type CrawlError struct {
+	Retryable       bool
+	Reason          string
+	HTTPStatus      int
+	BytesDownloaded int64
+	Err             error
+}
+
+func (e *CrawlError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+	}
+	return e.Reason
+}
+
+func (e *CrawlError) Unwrap() error {
+	return e.Err
+}
+
+func retryableError(reason string, err error) *CrawlError {
+	return &CrawlError{Retryable: true, Reason: reason, Err: err}
+}
+
+func permanentError(reason string, err error) *CrawlError {
+	return &CrawlError{Retryable: false, Reason: reason, Err: err}
+}
+
+// statusError classifies a non-200 response. Timeouts, throttling and server
+// errors are worth another attempt, everything else is not.
+func statusError(statusCode int) *CrawlError {
+	crawlErr := &CrawlError{
+		Reason:     fmt.Sprintf("non-200 status code: %d", statusCode),
+		HTTPStatus: statusCode,
+	}
+
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		crawlErr.Retryable = true
+	}
+	return crawlErr
+}
+
+func (e *CrawlError) withResponse(httpStatus int, bytesDownloaded int64) *CrawlError {
+	e.HTTPStatus = httpStatus
+	e.BytesDownloaded = bytesDownloaded
+	return e
+}
@@ -2,100 +2,304 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/MrBista/The-Crawler/internal/canonical"
+	"github.com/MrBista/The-Crawler/internal/content"
+	"github.com/MrBista/The-Crawler/internal/dedup"
+	"github.com/MrBista/The-Crawler/internal/document"
+	"github.com/MrBista/The-Crawler/internal/extract"
 	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/politeness"
 	"github.com/MrBista/The-Crawler/internal/queue"
 	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/robots"
+	"github.com/MrBista/The-Crawler/internal/scope"
 	"github.com/MrBista/The-Crawler/internal/storage"
+	"github.com/MrBista/The-Crawler/internal/webhook"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/google/uuid"
 )
 
+// maxBodyBytes membatasi body yang dibaca ke memori worker
+const maxBodyBytes = 64 << 20
+
 type CrawlHandler struct {
-	repo       repository.CrawlRepository
-	storage    storage.Storage
-	producer   *queue.Producer
-	kafkaTopic string
+	repo          repository.CrawlRepository
+	linkRepo      repository.LinkRepository
+	linkCheckRepo repository.LinkCheckRepository
+	versionRepo   repository.PageVersionRepository
+	watchRepo     repository.WatchRepository
+	jobRepo       repository.JobRepository
+	sessionRepo   repository.SessionRepository
+	visited       dedup.VisitedSet
+	canonicalizer *canonical.Canonicalizer
+	robots        *robots.Cache
+	limiter       politeness.Limiter
+	userAgent     string
+	storage       storage.Storage
+	webhookSender *webhook.Sender
+	producer      *queue.Producer
+	kafkaTopic    string
 }
 
-func NewCrawlHandler(repo repository.CrawlRepository, storage storage.Storage, producer *queue.Producer, topic string) *CrawlHandler {
+func NewCrawlHandler(repo repository.CrawlRepository, linkRepo repository.LinkRepository, linkCheckRepo repository.LinkCheckRepository, versionRepo repository.PageVersionRepository, watchRepo repository.WatchRepository, jobRepo repository.JobRepository, sessionRepo repository.SessionRepository, visited dedup.VisitedSet, canonicalizer *canonical.Canonicalizer, robotsCache *robots.Cache, limiter politeness.Limiter, userAgent string, storage storage.Storage, webhookSender *webhook.Sender, producer *queue.Producer, topic string) *CrawlHandler {
 	return &CrawlHandler{
-		repo:       repo,
-		storage:    storage,
-		producer:   producer,
-		kafkaTopic: topic,
+		repo:          repo,
+		linkRepo:      linkRepo,
+		linkCheckRepo: linkCheckRepo,
+		versionRepo:   versionRepo,
+		watchRepo:     watchRepo,
+		jobRepo:       jobRepo,
+		sessionRepo:   sessionRepo,
+		visited:       visited,
+		canonicalizer: canonicalizer,
+		robots:        robotsCache,
+		limiter:       limiter,
+		userAgent:     userAgent,
+		storage:       storage,
+		webhookSender: webhookSender,
+		producer:      producer,
+		kafkaTopic:    topic,
 	}
 }
 
-func (h *CrawlHandler) ProcessCrawl(job models.CrawlJob) {
+// ProcessCrawl fetches and stores one job. Failures are returned as
+// *CrawlError and left to the caller to retry or dead-letter; a context error
+// means the worker is stopping and the message should be redelivered.
+func (h *CrawlHandler) ProcessCrawl(ctx context.Context, job models.CrawlJob) (processErr error) {
 	log.Printf("[Worker] starting to crawl for: %s", job.Url)
 
+	// Pesan lama belum membawa session, anggap job sebagai root
+	if job.SessionId == "" {
+		job.SessionId = job.ID
+	}
+
+	if session, err := h.sessionRepo.FindSessionByID(job.SessionId); err == nil {
+		switch session.Status {
+		case models.SessionStatusCancelled:
+			log.Printf("[Worker] session %s cancelled, drop job %s", job.SessionId, job.ID)
+			h.finishJob(job, models.JobStatusCancelled, repository.JobTransition{FailureReason: "session cancelled"})
+			return nil
+		case models.SessionStatusPaused:
+			log.Printf("[Worker] session %s paused, park job %s", job.SessionId, job.ID)
+			h.parkJob(job)
+			return nil
+		}
+	}
+
 	if !strings.HasPrefix(job.Url, "http") {
 		log.Printf("[Worker] Invalid url schema: %s", job.Url)
-		return
+		h.finishJob(job, models.JobStatusSkipped, repository.JobTransition{FailureReason: "invalid url schema"})
+		return nil
+	}
+
+	if err := h.jobRepo.TransitionJob(job.ID, models.JobStatusFetching, repository.JobTransition{}); err != nil {
+		log.Printf("[Worker] job %s can not be fetched: %v", job.ID, err)
+		return nil
+	}
+
+	// Dicek setelah fetching supaya robots.txt yang sedang down bisa di-retry
+	// dengan backoff, hanya Disallow yang benar-benar membuat job dilewati
+	allowed, err := h.robots.Check(job.Url)
+	if err != nil {
+		log.Printf("[ROBOTS] %s for %s, retrying later", err, job.Url)
+		return retryableError("robots.txt unreachable", err)
+	}
+	if !allowed {
+		log.Printf("[ROBOTS] disallowed by robots.txt: %s", job.Url)
+		h.finishJob(job, models.JobStatusSkippedRobots, repository.JobTransition{FailureReason: "disallowed by robots.txt"})
+		return nil
+	}
+
+	if job.CheckOnly {
+		return h.checkLink(ctx, job)
+	}
+
+	if job.Mode == models.CrawlModeSitemap && job.ParentId == "" {
+		if err := h.seedFromSitemaps(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	if job.Mode == models.CrawlModeFeed && job.ParentId == "" {
+		return h.crawlFeed(ctx, job)
+	}
+
+	var matcher *scope.Matcher
+	if job.ParentId != "" && job.RootUrl != "" {
+		compiled, err := scope.Compile(job.RootUrl, job.Scope)
+		if err != nil {
+			return permanentError("invalid scope", err)
+		}
+		matcher = compiled
 	}
 
+	tracker := &redirectTracker{}
 	client := &http.Client{
-		Timeout: 20 * time.Second,
+		Timeout:       20 * time.Second,
+		CheckRedirect: h.checkRedirect(job, matcher, tracker),
 	}
 
 	req, err := http.NewRequest(http.MethodGet, job.Url, nil)
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	if err != nil {
+		log.Printf("[Worker] Failed to create request %s", job.Url)
+		return permanentError("failed to create request", err)
+	}
+
+	req.Header.Set("User-Agent", h.userAgent)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9,id;q=0.8")
 	req.Header.Set("Connection", "keep-alive")
 
+	previous := h.previousPage(job)
+	setValidators(req, previous)
+
+	release, err := h.limiter.Acquire(ctx, req.URL.Hostname(), h.robots.CrawlDelay(job.Url))
 	if err != nil {
-		log.Printf("[Worker] Failed to create request %s", job.Url)
-		return
+		// Worker sedang berhenti, job tetap fetching dan akan diproses ulang
+		log.Printf("[POLITENESS] failed to acquire slot for %s: %v", req.URL.Hostname(), err)
+		return err
 	}
-	res, err := client.Do(req)
+	defer release()
+
+	res, err := client.Do(req.WithContext(ctx))
 
 	if err != nil {
 		log.Printf("[Worker] Failed to get request %v", err)
-		return
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, errRedirectLoop) || errors.Is(err, errTooManyRedirects) {
+			return permanentError("failed to follow redirect", err)
+		}
+		return retryableError("failed to fetch", err)
 	}
 
 	defer res.Body.Close()
 
+	if tracker.stopReason != "" {
+		release()
+		return h.recordRedirect(job, res, tracker)
+	}
+
+	// Isi halaman milik URL terakhir, link relatif juga di-resolve dari sana
+	requestedUrl := job.Url
+	if len(tracker.hops) > 0 {
+		finalUrl, err := h.canonicalizer.Canonicalize(res.Request.URL.String())
+		if err != nil {
+			finalUrl = res.Request.URL.String()
+		}
+		if finalUrl != job.Url {
+			added, err := h.visited.MarkVisited(job.SessionId, finalUrl)
+			if err != nil {
+				log.Printf("[ERROR] failed to mark final url visited %s: %v", finalUrl, err)
+			} else if !added {
+				// Job lain sudah meng-crawl URL tujuan ini, mis. http dan https yang sama-sama redirect
+				release()
+				tracker.stopReason = "target already crawled in this session"
+				return h.recordRedirect(job, res, tracker)
+			} else {
+				// Percobaan ulang harus bisa menandai URL tujuan lagi
+				defer func() {
+					if processErr != nil {
+						if err := h.visited.Forget(job.SessionId, finalUrl); err != nil {
+							log.Printf("[ERROR] failed to forget final url %s: %v", finalUrl, err)
+						}
+					}
+				}()
+			}
+			job.Url = finalUrl
+		}
+	}
+
+	if res.StatusCode == http.StatusNotModified && previous != nil {
+		release()
+		return h.recordUnchanged(ctx, job, previous, requestedUrl, tracker.hops, res)
+	}
+
 	if res.StatusCode != 200 {
 		log.Printf("[Error] Non-200 Status Code: %d", res.StatusCode)
-		return
+		return statusError(res.StatusCode)
 	}
 
-	var bodyBytes bytes.Buffer
-	_, err = bodyBytes.ReadFrom(res.Body)
+	contentTypeHeader := res.Header.Get("Content-Type")
+
+	// Binary yang tidak disimpan dan body yang terlalu besar dilewati sebelum diunduh
+	if declared := document.Declared(contentTypeHeader); declared != "" && document.KindOf(declared) == document.KindBinary && job.BinaryPolicy != models.BinaryPolicyStore {
+		release()
+		h.skipDocument(job, res.StatusCode, fmt.Sprintf("unsupported content type %s", declared), 0)
+		return nil
+	}
+	if res.ContentLength > maxBodyBytes {
+		release()
+		h.skipDocument(job, res.StatusCode, fmt.Sprintf("body of %d bytes exceeds the %d byte limit", res.ContentLength, maxBodyBytes), 0)
+		return nil
+	}
+
+	rawHtml, err := io.ReadAll(io.LimitReader(res.Body, maxBodyBytes+1))
+	release()
 	if err != nil {
 		log.Printf("[Error] Failed to read boy: %v", err)
-		return
+		return retryableError("failed to read body", err).withResponse(res.StatusCode, 0)
+	}
+	bytesDownloaded := int64(len(rawHtml))
+	if bytesDownloaded > maxBodyBytes {
+		h.skipDocument(job, res.StatusCode, fmt.Sprintf("body exceeds the %d byte limit", maxBodyBytes), bytesDownloaded)
+		return nil
+	}
+
+	// Server tanpa ETag/Last-Modified tetap bisa dikenali tidak berubah lewat hash.
+	// Ekstraksi tetap dijalankan ulang, hanya file di storage yang tidak ditulis lagi
+	hash := contentHash(rawHtml)
+	var unchanged *models.CrawlPage
+	if previous != nil && previous.ContentHash == hash {
+		log.Printf("[RECRAWL] %s unchanged since %s", job.Url, previous.CreatedAt.Format(time.RFC3339))
+		unchanged = previous
 	}
 
-	rawHtml := bodyBytes.Bytes()
+	contentType := document.Sniff(contentTypeHeader, rawHtml)
+	if document.KindOf(contentType) != document.KindHTML {
+		return h.processDocument(ctx, job, requestedUrl, tracker.hops, res, contentType, hash, rawHtml, unchanged)
+	}
 
-	savePath, err := h.storage.Save(job.ID, rawHtml)
-	if err != nil {
+	var savePath string
+	if unchanged != nil {
+		savePath = unchanged.FilePath
+	} else if savePath, err = h.storage.Save(job.ID, rawHtml); err != nil {
 		log.Printf("[PROCESS_CRAWL_ERROR] failed to save file")
-		return
+		return retryableError("failed to save file", err).withResponse(res.StatusCode, bytesDownloaded)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(rawHtml))
+	// File di storage tetap byte asli, parser hanya menerima UTF-8
+	utf8Html, pageCharset := document.ToUTF8(rawHtml, contentTypeHeader)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(utf8Html))
 
 	if err != nil {
 		log.Printf("[PROCESS_CRAWL_ERROR] failed to get doc")
-		return
+		return permanentError("failed to parse html", err).withResponse(res.StatusCode, bytesDownloaded)
 	}
 
 	pageTitle := strings.TrimSpace(doc.Find("title").Text())
 	extractedData := make(models.JSONB)
 
-	if len(job.Selectors) > 0 {
+	if len(job.Schema) > 0 {
+		extractor, err := extract.Compile(job.Schema)
+		if err != nil {
+			return permanentError("invalid extraction schema", err).withResponse(res.StatusCode, bytesDownloaded)
+		}
+		extractedData = extractor.Extract(doc, job.Url)
+	} else if len(job.Selectors) > 0 {
 		for _, selector := range job.Selectors {
 			text := strings.TrimSpace(doc.Find(selector).Text())
 			if text != "" {
@@ -109,6 +313,22 @@ func (h *CrawlHandler) ProcessCrawl(job models.CrawlJob) {
 		}
 	}
 
+	article := content.Extract(doc, job.Url)
+
+	var markdownPath, textPath string
+	if unchanged != nil {
+		markdownPath, textPath = unchanged.MarkdownPath, unchanged.TextPath
+	} else {
+		if markdownPath, err = h.storage.SaveAs(job.ID, "md", []byte(article.Markdown)); err != nil {
+			log.Printf("[PROCESS_CRAWL_ERROR] failed to save markdown")
+			return retryableError("failed to save markdown", err).withResponse(res.StatusCode, bytesDownloaded)
+		}
+		if textPath, err = h.storage.SaveAs(job.ID, "txt", []byte(article.Text)); err != nil {
+			log.Printf("[PROCESS_CRAWL_ERROR] failed to save text")
+			return retryableError("failed to save text", err).withResponse(res.StatusCode, bytesDownloaded)
+		}
+	}
+
 	var parentIdPtr *string
 
 	if job.ParentId != "" {
@@ -116,86 +336,410 @@ func (h *CrawlHandler) ProcessCrawl(job models.CrawlJob) {
 	}
 
 	pageRecord := models.CrawlPage{
-		ID:         job.ID,
-		ParentID:   parentIdPtr,
-		URL:        job.Url,
-		Title:      pageTitle,
-		FilePath:   savePath,
-		ParsedData: extractedData,
-		Status:     "completed",
-		DepthLevel: job.Depth,
-		CreatedAt:  time.Now(),
-	}
-
-	if err := h.repo.SavePage(&pageRecord); err != nil {
+		ID:             job.ID,
+		ParentID:       parentIdPtr,
+		SessionID:      job.SessionId,
+		URL:            requestedUrl,
+		CanonicalURL:   h.pageCanonicalURL(doc, job),
+		FinalURL:       job.Url,
+		RedirectChain:  tracker.hops,
+		Title:          pageTitle,
+		ContentType:    contentType,
+		ContentLength:  bytesDownloaded,
+		Charset:        pageCharset,
+		FilePath:       savePath,
+		ETag:           res.Header.Get("ETag"),
+		LastModified:   res.Header.Get("Last-Modified"),
+		ContentHash:    hash,
+		ExtractHash:    extractHash(job),
+		ParsedData:     extractedData,
+		StructuredData: extract.Structured(doc, job.Url),
+		Byline:         article.Byline,
+		PublishedAt:    article.PublishedAt,
+		LeadImage:      article.LeadImage,
+		Excerpt:        article.Excerpt,
+		WordCount:      article.WordCount,
+		MarkdownPath:   markdownPath,
+		TextPath:       textPath,
+		Status:         models.JobStatusSucceeded,
+		DepthLevel:     job.Depth,
+		CreatedAt:      time.Now(),
+	}
+
+	if unchanged != nil {
+		pageRecord.Status = models.PageStatusUnchanged
+	}
+
+	withSitemapSource(&pageRecord, job)
+	withFeedItem(&pageRecord, job)
+
+	if err := h.savePage(&pageRecord, article.Text); err != nil {
 		log.Printf("[PROCESS_CRAWL_ERROR] failed to save page crawled")
-	} else {
-		log.Printf("[PROCESS_CRAWL] success to save page crawl")
+		return retryableError("failed to save page", err).withResponse(res.StatusCode, bytesDownloaded)
+	}
+	log.Printf("[PROCESS_CRAWL] success to save page crawl")
+
+	if job.WatchID != "" {
+		if err := h.evaluateWatch(ctx, job, &pageRecord); err != nil {
+			log.Printf("[WATCH_ERROR] failed to evaluate watch %s: %v", job.WatchID, err)
+			return retryableError("failed to evaluate watch", err).withResponse(res.StatusCode, bytesDownloaded)
+		}
+	}
+
+	links := h.collectLinks(doc, job)
+	if err := h.linkRepo.ReplaceLinks(job.ID, links); err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save page links")
+		return retryableError("failed to save links", err).withResponse(res.StatusCode, bytesDownloaded)
 	}
-	log.Printf("[NEXT] depth value %s", job.Depth)
+
+	log.Printf("[NEXT] depth value %d", job.Depth)
 	if job.Depth > 0 {
 		h.handleRecursiveLinks(doc, job)
 	}
+	if job.Mode == models.CrawlModeLinkCheck {
+		h.enqueueLinkChecks(job, links)
+	}
+
+	// Ditandai sukses setelah child di-enqueue supaya job tidak terlihat selesai
+	// sebelum turunannya tercatat
+	h.finishJob(job, models.JobStatusSucceeded, repository.JobTransition{
+		HTTPStatus:      res.StatusCode,
+		BytesDownloaded: bytesDownloaded,
+	})
+	return nil
+}
+
+// pageCanonicalURL honors <link rel="canonical"> and falls back to the job URL,
+// which is already canonical. A declared canonical is also marked visited so
+// links pointing at it don't crawl the same content again.
+func (h *CrawlHandler) pageCanonicalURL(doc *goquery.Document, job models.CrawlJob) string {
+	href, exists := doc.Find("link[rel='canonical']").First().Attr("href")
+	if !exists || strings.TrimSpace(href) == "" {
+		return job.Url
+	}
+
+	canonicalUrl, err := h.canonicalizer.Resolve(job.Url, href)
+	if err != nil || !strings.HasPrefix(canonicalUrl, "http") {
+		return job.Url
+	}
+
+	if canonicalUrl != job.Url {
+		if _, err := h.visited.MarkVisited(job.SessionId, canonicalUrl); err != nil {
+			log.Printf("[ERROR] failed to mark canonical url visited %s: %v", canonicalUrl, err)
+		}
+	}
+	return canonicalUrl
+}
+
+// collectLinks builds the outgoing edges of the page in DOM order. Unlike
+// handleRecursiveLinks it ignores depth and scope, the graph keeps every link.
+// In link_check mode images, scripts and stylesheets are collected as well.
+func (h *CrawlHandler) collectLinks(doc *goquery.Document, job models.CrawlJob) []models.PageLink {
+	sourceHost := strings.TrimPrefix(hostOf(job.Url), "www.")
+	links := make([]models.PageLink, 0)
+	now := time.Now()
+
+	selector := "a[href]"
+	if job.Mode == models.CrawlModeLinkCheck {
+		selector = "a[href], area[href], img[src], script[src], link[rel~=stylesheet][href]"
+	}
+
+	doc.Find(selector).Each(func(i int, s *goquery.Selection) {
+		kind, attr := models.LinkKindLink, "href"
+		switch goquery.NodeName(s) {
+		case "img":
+			kind, attr = models.LinkKindImage, "src"
+		case "script":
+			kind, attr = models.LinkKindScript, "src"
+		case "link":
+			kind = models.LinkKindStylesheet
+		}
+
+		href := strings.TrimSpace(s.AttrOr(attr, ""))
+		targetUrl, err := h.canonicalizer.Resolve(job.Url, href)
+		if err != nil || !strings.HasPrefix(targetUrl, "http") {
+			return
+		}
+
+		rel := strings.Fields(strings.ToLower(s.AttrOr("rel", "")))
+		nofollow := false
+		for _, value := range rel {
+			// sponsored dan ugc juga berarti link tidak boleh diikuti mesin pencari
+			if value == "nofollow" || value == "sponsored" || value == "ugc" {
+				nofollow = true
+			}
+		}
+
+		anchorText := strings.Join(strings.Fields(s.Text()), " ")
+		if anchorText == "" {
+			anchorText = strings.TrimSpace(s.Find("img[alt]").First().AttrOr("alt", ""))
+		}
+		if kind == models.LinkKindImage {
+			anchorText = strings.TrimSpace(s.AttrOr("alt", ""))
+		}
+
+		links = append(links, models.PageLink{
+			SessionID:    job.SessionId,
+			SourcePageID: job.ID,
+			SourceURL:    job.Url,
+			TargetURL:    targetUrl,
+			AnchorText:   anchorText,
+			Kind:         kind,
+			Rel:          strings.Join(rel, " "),
+			Nofollow:     nofollow,
+			Position:     len(links),
+			Internal:     strings.TrimPrefix(hostOf(targetUrl), "www.") == sourceHost,
+			CreatedAt:    now,
+		})
+	})
+	return links
+}
+
+// disallowedByRobots is true only for a Disallow rule. A child whose
+// robots.txt is unreachable is enqueued anyway, the worker retries it until
+// robots.txt answers.
+func (h *CrawlHandler) disallowedByRobots(rawUrl string) bool {
+	allowed, err := h.robots.Check(rawUrl)
+	return err == nil && !allowed
+}
+
+// parkJob keeps a job of a paused session until resume publishes it again.
+func (h *CrawlHandler) parkJob(job models.CrawlJob) {
+	payload, _ := json.Marshal(job)
+	parked := models.ParkedJob{
+		JobID:     job.ID,
+		SessionID: job.SessionId,
+		Payload:   string(payload),
+	}
+
+	if err := h.sessionRepo.ParkJob(&parked); err != nil {
+		log.Printf("[SESSION_ERROR] failed to park job %s: %v", job.ID, err)
+		return
+	}
+	if err := h.jobRepo.TransitionJob(job.ID, models.JobStatusPaused, repository.JobTransition{}); err != nil {
+		log.Printf("[JOB_STATUS_ERROR] failed to mark job %s as paused: %v", job.ID, err)
+	}
+
+	// Session bisa di-resume atau di-cancel di antara cek status dan park,
+	// job yang terlanjur diparkir diambil lagi di sini
+	session, err := h.sessionRepo.FindSessionByID(job.SessionId)
+	if err != nil || session.Status == models.SessionStatusPaused {
+		return
+	}
+	taken, err := h.sessionRepo.TakeParkedJob(job.ID)
+	if err != nil || taken == nil {
+		return
+	}
+
+	if session.Status == models.SessionStatusCancelled {
+		err = cancelParkedJob(h.jobRepo, h.sessionRepo, *taken)
+	} else {
+		err = requeueParkedJob(h.jobRepo, h.producer, h.kafkaTopic, *taken)
+	}
+	if err != nil {
+		log.Printf("[SESSION_ERROR] failed to unpark job %s: %v", job.ID, err)
+	}
+}
+
+func (h *CrawlHandler) failJob(job models.CrawlJob, crawlErr *CrawlError) {
+	h.finishJob(job, models.JobStatusFailed, repository.JobTransition{
+		FailureReason:   crawlErr.Error(),
+		HTTPStatus:      crawlErr.HTTPStatus,
+		BytesDownloaded: crawlErr.BytesDownloaded,
+	})
+
+	// Item feed yang gagal permanen dicoba lagi oleh poll feed berikutnya
+	if job.FeedItem != nil {
+		h.forgetFeedItem(feedDedupKey(job.FeedItem.FeedURL), job.FeedItem.GUID)
+	}
+}
+
+func (h *CrawlHandler) finishJob(job models.CrawlJob, status string, transition repository.JobTransition) {
+	if err := h.jobRepo.TransitionJob(job.ID, status, transition); err != nil {
+		log.Printf("[JOB_STATUS_ERROR] failed to mark job %s as %s: %v", job.ID, status, err)
+		return
+	}
+
+	if err := h.sessionRepo.RecordResult(job.SessionId, status, transition.BytesDownloaded); err != nil {
+		log.Printf("[SESSION_ERROR] failed to record job %s in session %s: %v", job.ID, job.SessionId, err)
+	}
+
+	if job.Mode == models.CrawlModeLinkCheck && !job.CheckOnly {
+		h.recordCrawlCheck(job, status, transition)
+	}
 }
 
 func (h *CrawlHandler) handleRecursiveLinks(doc *goquery.Document, parentJob models.CrawlJob) {
 	log.Printf("[CRAWL_RECURSIVE] START TO RECURSIVE TASK LINK")
-	visitedLinks := make(map[string]bool)
 
+	hrefs := make([]string, 0)
 	doc.Find("a").Each(func(i int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if !exists {
-			return
+		if href, exists := s.Attr("href"); exists {
+			hrefs = append(hrefs, href)
 		}
+	})
+	h.enqueueLinks(parentJob, hrefs)
+}
+
+// enqueueLinks resolves hrefs against the parent URL and enqueues the ones in
+// scope that the session has not visited yet, until the session stops taking
+// jobs.
+func (h *CrawlHandler) enqueueLinks(parentJob models.CrawlJob, hrefs []string) {
+	visitedLinks := make(map[string]bool)
+
+	if parentJob.RootUrl == "" {
+		parentJob.RootUrl = parentJob.Url
+	}
 
-		absoluteURl := resolveURL(parentJob.Url, href)
-		if absoluteURl == "" || visitedLinks[absoluteURl] || !strings.HasPrefix(absoluteURl, "http") {
+	matcher, err := scope.Compile(parentJob.RootUrl, parentJob.Scope)
+	if err != nil {
+		log.Printf("[ERROR_RECURSIVE] invalid scope for job %s: %v", parentJob.ID, err)
+		return
+	}
+
+	for _, href := range hrefs {
+		absoluteURl, err := h.canonicalizer.Resolve(parentJob.Url, href)
+		if err != nil || visitedLinks[absoluteURl] || !strings.HasPrefix(absoluteURl, "http") {
 			log.Printf("[ERROR_RECURSIVE] failed to recursive links")
-			return
+			continue
 		}
 		visitedLinks[absoluteURl] = true
 
 		if absoluteURl == parentJob.Url {
-			return
+			continue
 		}
 
 		newDepth := parentJob.Depth - 1
 
 		if newDepth < 0 {
-			return
+			continue
 		}
 
-		uidChild := uuid.New().String()
-		childJob := models.CrawlJob{
-			ID:        uidChild,
-			ParentId:  parentJob.ID,
-			Url:       absoluteURl,
-			Depth:     newDepth,
-			Selectors: parentJob.Selectors,
+		absoluteURl, inScope := matcher.Apply(absoluteURl)
+		if !inScope {
+			continue
 		}
 
-		payload, _ := json.Marshal(childJob)
+		// Visited set dibagi satu session, jadi halaman saudara tidak meng-enqueue URL yang sama
+		added, err := h.visited.MarkVisited(parentJob.SessionId, absoluteURl)
+		if err != nil {
+			log.Printf("[ERROR] failed to check visited url %s: %v", absoluteURl, err)
+			continue
+		}
+		if !added {
+			continue
+		}
 
-		partion, offset, err := h.producer.PublishMessage(h.kafkaTopic, uidChild, string(payload))
+		if _, more := h.enqueueChild(parentJob, h.newChildJob(parentJob, absoluteURl, newDepth)); !more {
+			return
+		}
+	}
+}
 
-		log.Printf("[Queue] Enqueue Child: %s | Depth: %d -> %d", childJob.Url, parentJob.Depth, childJob.Depth)
+// newChildJob copies everything a child must inherit from its parent.
+func (h *CrawlHandler) newChildJob(parentJob models.CrawlJob, childUrl string, depth int) models.CrawlJob {
+	return models.CrawlJob{
+		ID:             uuid.New().String(),
+		ParentId:       parentJob.ID,
+		SessionId:      parentJob.SessionId,
+		Url:            childUrl,
+		Depth:          depth,
+		Selectors:      parentJob.Selectors,
+		Schema:         parentJob.Schema,
+		RootUrl:        parentJob.RootUrl,
+		Scope:          parentJob.Scope,
+		Mode:           parentJob.Mode,
+		RedirectPolicy: parentJob.RedirectPolicy,
+		BinaryPolicy:   parentJob.BinaryPolicy,
+	}
+}
 
-		if err != nil {
-			log.Printf("[ERROR] failed to publish message")
+// enqueueChild records and publishes childJob within the session budget.
+// enqueued tells whether the child will be fetched, published or parked until
+// resume. more is false once the session takes no more jobs so callers can
+// stop discovering links.
+func (h *CrawlHandler) enqueueChild(parentJob, childJob models.CrawlJob) (enqueued, more bool) {
+	parentId := parentJob.ID
+	childRecord := models.CrawlJobRecord{
+		ID:        childJob.ID,
+		ParentID:  &parentId,
+		SessionID: childJob.SessionId,
+		URL:       childJob.Url,
+		Depth:     childJob.Depth,
+	}
+
+	// Tetap dicatat sebagai job supaya URL yang diblokir robots.txt terlihat,
+	// tapi tidak memakai budget session. Probe link diperiksa robots-nya oleh worker.
+	if !childJob.CheckOnly && h.disallowedByRobots(childJob.Url) {
+		log.Printf("[ROBOTS] child disallowed by robots.txt: %s", childJob.Url)
+		if err := h.jobRepo.CreateJob(&childRecord); err != nil {
+			log.Printf("[ERROR] failed to record child job %s: %v", childJob.Url, err)
+			return false, true
+		}
+		if err := h.sessionRepo.IncrementQueued(childJob.SessionId, 1); err != nil {
+			log.Printf("[SESSION_ERROR] failed to count child job %s: %v", childJob.ID, err)
 		}
+		h.finishJob(childJob, models.JobStatusSkippedRobots, repository.JobTransition{FailureReason: "disallowed by robots.txt"})
+		return false, true
+	}
 
-		log.Printf("[CHILD_RECURSIVE] success to send to partion (%s) and offset (%s)", partion, offset)
-	})
-}
+	var reservation repository.SlotReservation
+	var err error
+	if childJob.CheckOnly {
+		reservation, err = h.sessionRepo.ReserveCheckSlot(childJob.SessionId)
+	} else {
+		reservation, err = h.sessionRepo.ReserveSlot(childJob.SessionId, hostOf(childJob.Url))
+	}
+	if err != nil {
+		log.Printf("[SESSION_ERROR] failed to reserve budget for %s: %v", childJob.Url, err)
+		return false, true
+	}
+	switch reservation {
+	case repository.SlotHostLimit:
+		log.Printf("[BUDGET] host page limit reached, skip %s", childJob.Url)
+		return false, true
+	case repository.SlotBudgetExhausted:
+		log.Printf("[BUDGET] session %s budget exhausted", childJob.SessionId)
+		return false, false
+	case repository.SlotSessionStopped:
+		log.Printf("[BUDGET] session %s no longer running", childJob.SessionId)
+		return false, false
+	}
+
+	if err := h.jobRepo.CreateJob(&childRecord); err != nil {
+		log.Printf("[ERROR] failed to record child job %s: %v", childJob.Url, err)
+		// Slot sudah dipesan, dihitung gagal supaya session tetap bisa selesai
+		if err := h.sessionRepo.RecordResult(childJob.SessionId, models.JobStatusFailed, 0); err != nil {
+			log.Printf("[SESSION_ERROR] failed to release slot of %s: %v", childJob.Url, err)
+		}
+		return false, true
+	}
+
+	if reservation == repository.SlotReservedPaused {
+		h.parkJob(childJob)
+		return true, true
+	}
+
+	payload, _ := json.Marshal(childJob)
+
+	partion, offset, err := h.producer.PublishMessage(h.kafkaTopic, childJob.ID, string(payload))
+
+	log.Printf("[Queue] Enqueue Child: %s | Depth: %d -> %d", childJob.Url, parentJob.Depth, childJob.Depth)
 
-func resolveURL(baseUrl, href string) string {
-	base, err := url.Parse(baseUrl)
 	if err != nil {
-		return ""
+		log.Printf("[ERROR] failed to publish message")
+		h.failJob(childJob, retryableError("failed to publish job", err))
+		return false, true
 	}
-	ref, err := url.Parse(href)
+
+	log.Printf("[CHILD_RECURSIVE] success to send to partion (%d) and offset (%d)", partion, offset)
+	return true, true
+}
+
+func hostOf(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
 	if err != nil {
 		return ""
 	}
-	return base.ResolveReference(ref).String()
+	return strings.ToLower(u.Hostname())
 }
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type DeadLetterApiHandler struct {
+	deadLetterRepo repository.DeadLetterRepository
+	jobRepo        repository.JobRepository
+	sessionRepo    repository.SessionRepository
+	producer       *queue.Producer
+	kafkaTopic     string
+}
+
+func NewDeadLetterApiHandler(deadLetterRepo repository.DeadLetterRepository, jobRepo repository.JobRepository, sessionRepo repository.SessionRepository, producer *queue.Producer, topic string) *DeadLetterApiHandler {
+	return &DeadLetterApiHandler{
+		deadLetterRepo: deadLetterRepo,
+		jobRepo:        jobRepo,
+		sessionRepo:    sessionRepo,
+		producer:       producer,
+		kafkaTopic:     topic,
+	}
+}
+
+func (h *DeadLetterApiHandler) ListDeadLetters(c *fiber.Ctx) error {
+	page, limit := parsePagination(c)
+	includeReplayed := c.QueryBool("include_replayed", false)
+
+	deadLetters, total, err := h.deadLetterRepo.FindDeadLetters(includeReplayed, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find dead letters: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get dead letters",
+		})
+	}
+
+	if deadLetters == nil {
+		deadLetters = []models.DeadLetter{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": deadLetters,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *DeadLetterApiHandler) ReplayDeadLetter(c *fiber.Ctx) error {
+	deadLetterId := c.Params("id")
+
+	deadLetter, err := h.deadLetterRepo.FindDeadLetterByID(deadLetterId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "dead letter not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find dead letter %s: %v", deadLetterId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get dead letter",
+		})
+	}
+
+	if deadLetter.ReplayedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"data":    nil,
+			"message": "dead letter already replayed",
+		})
+	}
+
+	var job models.CrawlJob
+	if err := json.Unmarshal([]byte(deadLetter.Payload), &job); err != nil || job.ID == "" {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"data":    nil,
+			"message": "dead letter payload is not a crawl job",
+		})
+	}
+
+	// Replay mulai dari attempt pertama lagi
+	job.Attempt = 0
+	job.NotBefore = nil
+	if job.SessionId == "" {
+		job.SessionId = job.ID
+	}
+
+	if err := h.jobRepo.TransitionJob(job.ID, models.JobStatusQueued, repository.JobTransition{}); err != nil {
+		log.Printf("[API_ERROR] failed to requeue job %s: %v", job.ID, err)
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"data":    nil,
+			"message": "crawl job can not be replayed",
+		})
+	}
+
+	if err := h.sessionRepo.RequeueJob(job.SessionId); err != nil {
+		log.Printf("[API_ERROR] failed to requeue job %s in session %s: %v", job.ID, job.SessionId, err)
+	}
+
+	payload, _ := json.Marshal(job)
+	if _, _, err := h.producer.PublishMessage(h.kafkaTopic, job.ID, string(payload)); err != nil {
+		transition := repository.JobTransition{FailureReason: fmt.Sprintf("failed to publish replay: %v", err)}
+		if err := h.jobRepo.TransitionJob(job.ID, models.JobStatusFailed, transition); err != nil {
+			log.Printf("[API_ERROR] failed to mark job %s as failed: %v", job.ID, err)
+		} else if err := h.sessionRepo.RecordResult(job.SessionId, models.JobStatusFailed, 0); err != nil {
+			log.Printf("[API_ERROR] failed to record job %s in session: %v", job.ID, err)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to publish job",
+		})
+	}
+
+	if err := h.deadLetterRepo.MarkReplayed(deadLetter.ID); err != nil {
+		log.Printf("[API_ERROR] failed to mark dead letter %s replayed: %v", deadLetter.ID, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{
+			"dead_letter_id": deadLetter.ID,
+			"job_id":         job.ID,
+			"status":         models.JobStatusQueued,
+		},
+	})
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/document"
+	"github.com/MrBista/The-Crawler/internal/extract"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+)
+
+// skipDocument ends a job whose response is not worth keeping. bytes is 0
+// when the body was never read.
+func (h *CrawlHandler) skipDocument(job models.CrawlJob, statusCode int, reason string, bytes int64) {
+	log.Printf("[DOCUMENT] skip %s, %s", job.Url, reason)
+	h.finishJob(job, models.JobStatusSkipped, repository.JobTransition{
+		FailureReason:   reason,
+		HTTPStatus:      statusCode,
+		BytesDownloaded: bytes,
+	})
+}
+
+// processDocument stores a response that is not HTML under its own extension
+// and records what can be read from it. unchanged is the previous crawl when
+// the body is identical, its files are reused instead of written again.
+// Documents have no links, so the job ends here without discovering children.
+func (h *CrawlHandler) processDocument(ctx context.Context, job models.CrawlJob, requestedUrl string, hops models.RedirectChain, res *http.Response, contentType, hash string, body []byte, unchanged *models.CrawlPage) error {
+	statusCode := res.StatusCode
+	kind := document.KindOf(contentType)
+	bytesDownloaded := int64(len(body))
+
+	if kind == document.KindBinary && job.BinaryPolicy != models.BinaryPolicyStore {
+		h.skipDocument(job, statusCode, fmt.Sprintf("unsupported content type %s", contentType), bytesDownloaded)
+		return nil
+	}
+
+	var (
+		savePath, textPath string
+		err                error
+	)
+	if unchanged != nil {
+		savePath, textPath = unchanged.FilePath, unchanged.TextPath
+	} else if savePath, err = h.storage.SaveAs(job.ID, document.Extension(contentType), body); err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save %s file", kind)
+		return retryableError("failed to save file", err).withResponse(statusCode, bytesDownloaded)
+	}
+
+	var parentIdPtr *string
+	if job.ParentId != "" {
+		parentIdPtr = &job.ParentId
+	}
+
+	pageRecord := models.CrawlPage{
+		ID:            job.ID,
+		ParentID:      parentIdPtr,
+		SessionID:     job.SessionId,
+		URL:           requestedUrl,
+		CanonicalURL:  job.Url,
+		FinalURL:      job.Url,
+		RedirectChain: hops,
+		ContentType:   contentType,
+		ContentLength: bytesDownloaded,
+		FilePath:      savePath,
+		TextPath:      textPath,
+		ETag:          res.Header.Get("ETag"),
+		LastModified:  res.Header.Get("Last-Modified"),
+		ContentHash:   hash,
+		ExtractHash:   extractHash(job),
+		ParsedData:    make(models.JSONB),
+		Status:        models.JobStatusSucceeded,
+		DepthLevel:    job.Depth,
+		CreatedAt:     time.Now(),
+	}
+	if unchanged != nil {
+		pageRecord.Status = models.PageStatusUnchanged
+	}
+
+	// text menjadi dasar riwayat versi, kosong untuk gambar dan binary
+	var text string
+	switch kind {
+	case document.KindPDF:
+		if text, err = h.describePDF(&pageRecord, body); err != nil {
+			return retryableError("failed to save text", err).withResponse(statusCode, bytesDownloaded)
+		}
+	case document.KindJSON:
+		if err := describeJSON(&pageRecord, job, body); err != nil {
+			return permanentError("invalid extraction schema", err).withResponse(statusCode, bytesDownloaded)
+		}
+		decoded, _ := document.ToUTF8(body, "")
+		text = string(decoded)
+	case document.KindImage:
+		if width, height, ok := document.ImageSize(body); ok {
+			pageRecord.ImageWidth = width
+			pageRecord.ImageHeight = height
+		}
+	case document.KindText:
+		if text, err = h.describeText(&pageRecord, res.Header.Get("Content-Type"), body); err != nil {
+			return retryableError("failed to save text", err).withResponse(statusCode, bytesDownloaded)
+		}
+	}
+
+	withSitemapSource(&pageRecord, job)
+	withFeedItem(&pageRecord, job)
+
+	if err := h.savePage(&pageRecord, text); err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save page crawled")
+		return retryableError("failed to save page", err).withResponse(statusCode, bytesDownloaded)
+	}
+	log.Printf("[DOCUMENT] saved %s document %s (%d bytes)", kind, job.Url, bytesDownloaded)
+
+	if job.WatchID != "" {
+		if err := h.evaluateWatch(ctx, job, &pageRecord); err != nil {
+			log.Printf("[WATCH_ERROR] failed to evaluate watch %s: %v", job.WatchID, err)
+			return retryableError("failed to evaluate watch", err).withResponse(statusCode, bytesDownloaded)
+		}
+	}
+
+	h.finishJob(job, models.JobStatusSucceeded, repository.JobTransition{
+		HTTPStatus:      statusCode,
+		BytesDownloaded: bytesDownloaded,
+	})
+	return nil
+}
+
+// describePDF fills the page from the PDF info dictionary and saves the text
+// layer next to the original file, unless the page already reuses one. It
+// returns the text layer.
+func (h *CrawlHandler) describePDF(page *models.CrawlPage, body []byte) (string, error) {
+	info := document.ParsePDF(body)
+
+	page.Title = info.Title
+	page.Byline = info.Author
+	page.PublishedAt = info.CreatedAt
+	page.PageCount = info.PageCount
+	page.StructuredData = models.JSONB{
+		"pdf": map[string]interface{}{
+			"subject":   info.Subject,
+			"keywords":  info.Keywords,
+			"creator":   info.Creator,
+			"producer":  info.Producer,
+			"encrypted": info.Encrypted,
+		},
+	}
+
+	if info.Text == "" {
+		return "", nil
+	}
+	if page.TextPath == "" {
+		textPath, err := h.storage.SaveAs(page.ID, "txt", []byte(info.Text))
+		if err != nil {
+			log.Printf("[PROCESS_CRAWL_ERROR] failed to save pdf text")
+			return "", err
+		}
+		page.TextPath = textPath
+	}
+	page.WordCount = len(strings.Fields(info.Text))
+	page.Excerpt = excerptOf(info.Text)
+	return info.Text, nil
+}
+
+// describeText records the encoding of a text document. The original file is
+// reused as the text file when it is already UTF-8, otherwise a transcoded
+// copy is saved beside it unless the page already reuses one. It returns the
+// text as UTF-8.
+func (h *CrawlHandler) describeText(page *models.CrawlPage, contentTypeHeader string, body []byte) (string, error) {
+	text, encoding := document.ToUTF8(body, contentTypeHeader)
+	page.Charset = encoding
+	page.WordCount = len(strings.Fields(string(text)))
+
+	if encoding == "utf-8" {
+		page.TextPath = page.FilePath
+		return string(text), nil
+	}
+	if page.TextPath != "" {
+		return string(text), nil
+	}
+	// Ekstensi berbeda supaya file asli .txt tidak tertimpa
+	textPath, err := h.storage.SaveAs(page.ID, "utf8.txt", text)
+	if err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save text")
+		return "", err
+	}
+	page.TextPath = textPath
+	return string(text), nil
+}
+
+// describeJSON runs the Path fields of the job schema against the document.
+// A body that is not valid JSON is still stored, only without parsed data.
+func describeJSON(page *models.CrawlPage, job models.CrawlJob, body []byte) error {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		log.Printf("[DOCUMENT] invalid json from %s: %v", job.Url, err)
+		return nil
+	}
+
+	if len(job.Schema) == 0 {
+		return nil
+	}
+	extractor, err := extract.Compile(job.Schema)
+	if err != nil {
+		return err
+	}
+	page.ParsedData = extractor.ExtractJSON(decoded, job.Url)
+	return nil
+}
+
+func excerptOf(text string) string {
+	const maxExcerpt = 300
+	excerpt := strings.Join(strings.Fields(text), " ")
+	runes := []rune(excerpt)
+	if len(runes) <= maxExcerpt {
+		return excerpt
+	}
+	return string(runes[:maxExcerpt]) + "…"
+}
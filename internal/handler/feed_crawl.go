@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/feed"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/scope"
+)
+
+const maxFeedBytes = 10 << 20
+
+// crawlFeed fetches the feed at job.Url and enqueues the link of every item
+// whose GUID was not seen by an earlier poll of the same feed. The feed itself
+// is saved as the root page. failJob forgets the GUID again when the item's
+// job fails permanently, so the next poll retries it.
+func (h *CrawlHandler) crawlFeed(ctx context.Context, job models.CrawlJob) error {
+	body, status, err := h.fetchFeed(ctx, job)
+	if err != nil {
+		return err
+	}
+	bytesDownloaded := int64(len(body))
+
+	parsed, err := feed.Parse(body, job.Url)
+	if err != nil {
+		log.Printf("[FEED_ERROR] failed to parse feed %s: %v", job.Url, err)
+		return permanentError("failed to parse feed", err).withResponse(status, bytesDownloaded)
+	}
+
+	extension := "xml"
+	if parsed.Type == feed.TypeJSONFeed {
+		extension = "json"
+	}
+	savePath, err := h.storage.SaveAs(job.ID, extension, body)
+	if err != nil {
+		log.Printf("[FEED_ERROR] failed to save feed file")
+		return retryableError("failed to save file", err).withResponse(status, bytesDownloaded)
+	}
+
+	rootUrl := job.RootUrl
+	if rootUrl == "" {
+		rootUrl = job.Url
+	}
+	matcher, err := scope.Compile(rootUrl, job.Scope)
+	if err != nil {
+		return permanentError("invalid scope", err).withResponse(status, bytesDownloaded)
+	}
+
+	feedKey := feedDedupKey(job.Url)
+	newItems := 0
+	for _, item := range parsed.Items {
+		itemUrl, err := h.canonicalizer.Canonicalize(item.Link)
+		if err != nil || !strings.HasPrefix(itemUrl, "http") {
+			continue
+		}
+		itemUrl, inScope := matcher.Apply(itemUrl)
+		if !inScope {
+			continue
+		}
+
+		// GUID dicatat per feed, bukan per session, supaya poll berikutnya hanya mengambil item baru
+		added, err := h.visited.MarkVisited(feedKey, item.GUID)
+		if err != nil {
+			log.Printf("[ERROR] failed to check feed guid %s: %v", item.GUID, err)
+			continue
+		}
+		if !added {
+			continue
+		}
+		// URL yang sudah ada di session tetap di-crawl oleh job lain, GUID-nya boleh dianggap terlihat
+		urlAdded, err := h.visited.MarkVisited(job.SessionId, itemUrl)
+		if err != nil {
+			log.Printf("[ERROR] failed to check visited url %s: %v", itemUrl, err)
+			h.forgetFeedItem(feedKey, item.GUID)
+			continue
+		}
+		if !urlAdded {
+			continue
+		}
+
+		childJob := h.newChildJob(job, itemUrl, job.Depth)
+		childJob.Mode = models.CrawlModeCrawl
+		childJob.FeedItem = &models.FeedItem{
+			FeedURL:   job.Url,
+			GUID:      item.GUID,
+			Title:     item.Title,
+			Author:    item.Author,
+			Published: item.Published,
+		}
+		enqueued, more := h.enqueueChild(job, childJob)
+		if enqueued {
+			newItems++
+		} else {
+			// Item yang tidak jadi di-enqueue harus tetap terlihat baru di poll berikutnya
+			h.forgetFeedItem(feedKey, item.GUID)
+		}
+		if !more {
+			break
+		}
+	}
+
+	var parentIdPtr *string
+	if job.ParentId != "" {
+		parentIdPtr = &job.ParentId
+	}
+
+	pageRecord := models.CrawlPage{
+		ID:           job.ID,
+		ParentID:     parentIdPtr,
+		SessionID:    job.SessionId,
+		URL:          job.Url,
+		CanonicalURL: job.Url,
+		FinalURL:     job.Url,
+		Title:        parsed.Title,
+		FilePath:     savePath,
+		ParsedData: models.JSONB{
+			"feed_type":  parsed.Type,
+			"item_count": len(parsed.Items),
+			"new_items":  newItems,
+		},
+		Status:     models.JobStatusSucceeded,
+		DepthLevel: job.Depth,
+		CreatedAt:  time.Now(),
+	}
+	if err := h.repo.SavePage(&pageRecord); err != nil {
+		log.Printf("[FEED_ERROR] failed to save feed page")
+		return retryableError("failed to save page", err).withResponse(status, bytesDownloaded)
+	}
+
+	log.Printf("[FEED] %s: %d items, %d new", job.Url, len(parsed.Items), newItems)
+	h.finishJob(job, models.JobStatusSucceeded, repository.JobTransition{
+		HTTPStatus:      status,
+		BytesDownloaded: bytesDownloaded,
+	})
+	return nil
+}
+
+func (h *CrawlHandler) forgetFeedItem(feedKey, guid string) {
+	if err := h.visited.Forget(feedKey, guid); err != nil {
+		log.Printf("[ERROR] failed to forget feed guid %s: %v", guid, err)
+	}
+}
+
+func (h *CrawlHandler) fetchFeed(ctx context.Context, job models.CrawlJob) ([]byte, int, error) {
+	tracker := &redirectTracker{}
+	client := &http.Client{
+		Timeout:       20 * time.Second,
+		CheckRedirect: h.checkRedirect(job, nil, tracker),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.Url, nil)
+	if err != nil {
+		return nil, 0, permanentError("failed to create request", err)
+	}
+	req.Header.Set("User-Agent", h.userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.5")
+
+	release, err := h.limiter.Acquire(ctx, req.URL.Hostname(), h.robots.CrawlDelay(job.Url))
+	if err != nil {
+		log.Printf("[POLITENESS] failed to acquire slot for %s: %v", req.URL.Hostname(), err)
+		return nil, 0, err
+	}
+	defer release()
+
+	res, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		if errors.Is(err, errRedirectLoop) || errors.Is(err, errTooManyRedirects) {
+			return nil, 0, permanentError("failed to follow redirect", err)
+		}
+		return nil, 0, retryableError("failed to fetch", err)
+	}
+	defer res.Body.Close()
+
+	if tracker.stopReason != "" {
+		return nil, 0, permanentError("redirect not followed: "+tracker.stopReason, nil).withResponse(res.StatusCode, 0)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, 0, statusError(res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxFeedBytes))
+	if err != nil {
+		return nil, 0, retryableError("failed to read body", err).withResponse(res.StatusCode, 0)
+	}
+	return body, res.StatusCode, nil
+}
+
+// feedDedupKey is the visited set namespace holding the GUIDs of one feed.
+// The URL is hashed because the namespace column is varchar(255).
+func feedDedupKey(feedUrl string) string {
+	sum := sha1.Sum([]byte(feedUrl))
+	return "feed:" + hex.EncodeToString(sum[:])
+}
+
+func withFeedItem(page *models.CrawlPage, job models.CrawlJob) {
+	if job.FeedItem == nil {
+		return
+	}
+	page.FeedURL = job.FeedItem.FeedURL
+	page.FeedGUID = job.FeedItem.GUID
+	page.FeedItemTitle = job.FeedItem.Title
+	page.FeedAuthor = job.FeedItem.Author
+	page.FeedPublishedAt = job.FeedItem.Published
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/scope"
+)
+
+const (
+	linkCheckTimeout   = 15 * time.Second
+	linkCheckRedirects = 10
+)
+
+// linkProbe is the outcome of one HEAD or GET request, redirects followed by hand
+// so every hop is recorded.
+type linkProbe struct {
+	method     string
+	statusCode int
+	finalUrl   string
+	chain      []string
+	err        error
+}
+
+// enqueueLinkChecks queues a check job for every URL the page references. Each
+// URL is checked once per session, the "check:" prefix keeps these marks apart
+// from the pages the crawl itself visits. Links the crawl follows are not
+// probed, the GET of their own job is recorded as their check.
+func (h *CrawlHandler) enqueueLinkChecks(job models.CrawlJob, links []models.PageLink) {
+	var matcher *scope.Matcher
+	if job.Depth > 0 {
+		rootUrl := job.RootUrl
+		if rootUrl == "" {
+			rootUrl = job.Url
+		}
+		matcher, _ = scope.Compile(rootUrl, job.Scope)
+	}
+
+	for _, link := range links {
+		if link.TargetURL == job.Url {
+			continue
+		}
+		if matcher != nil && link.Kind == models.LinkKindLink {
+			if target, inScope := matcher.Apply(link.TargetURL); inScope && target == link.TargetURL {
+				continue
+			}
+		}
+
+		added, err := h.visited.MarkVisited(job.SessionId, "check:"+link.TargetURL)
+		if err != nil {
+			log.Printf("[ERROR] failed to check visited url %s: %v", link.TargetURL, err)
+			continue
+		}
+		if !added {
+			continue
+		}
+
+		checkJob := h.newChildJob(job, link.TargetURL, 0)
+		checkJob.CheckOnly = true
+		if _, more := h.enqueueChild(job, checkJob); !more {
+			return
+		}
+	}
+}
+
+// recordCrawlCheck stores the outcome of a page crawled by a link_check
+// session as the check of its URL. Retried failures only count once they are
+// final.
+func (h *CrawlHandler) recordCrawlCheck(job models.CrawlJob, status string, transition repository.JobTransition) {
+	if status != models.JobStatusSucceeded && status != models.JobStatusFailed {
+		return
+	}
+
+	check := models.LinkCheck{
+		SessionID:     job.SessionId,
+		URL:           job.Url,
+		JobID:         job.ID,
+		Method:        http.MethodGet,
+		StatusCode:    transition.HTTPStatus,
+		FinalURL:      job.Url,
+		RedirectChain: models.StringList{},
+		Broken:        status == models.JobStatusFailed || transition.HTTPStatus >= 400,
+		CheckedAt:     time.Now(),
+	}
+	if status == models.JobStatusFailed {
+		check.Error = transition.FailureReason
+	}
+
+	if err := h.linkCheckRepo.SaveLinkCheck(&check); err != nil {
+		log.Printf("[LINK_CHECK_ERROR] failed to save crawl result of %s: %v", job.Url, err)
+	}
+}
+
+// checkLink probes job.Url with HEAD, falling back to GET when the server
+// rejects HEAD, and records the result. Broken links, timeouts and network
+// errors are results, not job failures, so they are never retried.
+func (h *CrawlHandler) checkLink(ctx context.Context, job models.CrawlJob) error {
+	release, err := h.limiter.Acquire(ctx, hostOf(job.Url), h.robots.CrawlDelay(job.Url))
+	if err != nil {
+		log.Printf("[POLITENESS] failed to acquire slot for %s: %v", hostOf(job.Url), err)
+		return err
+	}
+
+	started := time.Now()
+	probe := h.probeLink(ctx, http.MethodHead, job.Url)
+	// Banyak server tidak mendukung HEAD dengan benar, hasil gagal dicoba ulang dengan GET
+	if probe.err == nil && probe.statusCode >= 400 {
+		probe = h.probeLink(ctx, http.MethodGet, job.Url)
+	}
+	release()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	check := models.LinkCheck{
+		SessionID:     job.SessionId,
+		URL:           job.Url,
+		JobID:         job.ID,
+		Method:        probe.method,
+		StatusCode:    probe.statusCode,
+		FinalURL:      probe.finalUrl,
+		RedirectChain: probe.chain,
+		DurationMs:    time.Since(started).Milliseconds(),
+		CheckedAt:     time.Now(),
+	}
+	if probe.err != nil {
+		check.Error = probe.err.Error()
+		check.TimedOut = isTimeout(probe.err)
+	}
+	check.Broken = probe.err != nil || probe.statusCode >= 400
+
+	if err := h.linkCheckRepo.SaveLinkCheck(&check); err != nil {
+		log.Printf("[LINK_CHECK_ERROR] failed to save result of %s: %v", job.Url, err)
+		return retryableError("failed to save link check", err)
+	}
+
+	log.Printf("[LINK_CHECK] %s %s -> %d (broken: %t)", probe.method, job.Url, probe.statusCode, check.Broken)
+	h.finishJob(job, models.JobStatusSucceeded, repository.JobTransition{HTTPStatus: probe.statusCode})
+	return nil
+}
+
+func (h *CrawlHandler) probeLink(ctx context.Context, method, rawUrl string) linkProbe {
+	client := &http.Client{
+		Timeout: linkCheckTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	probe := linkProbe{method: method, finalUrl: rawUrl, chain: []string{}}
+	current := rawUrl
+	for hop := 0; ; hop++ {
+		req, err := http.NewRequestWithContext(ctx, method, current, nil)
+		if err != nil {
+			probe.err = err
+			return probe
+		}
+		req.Header.Set("User-Agent", h.userAgent)
+		req.Header.Set("Accept", "*/*")
+
+		res, err := client.Do(req)
+		if err != nil {
+			probe.err = err
+			return probe
+		}
+		// Body GET tidak dibaca, cukup status code-nya
+		res.Body.Close()
+
+		probe.statusCode = res.StatusCode
+		probe.finalUrl = current
+
+		location := res.Header.Get("Location")
+		if res.StatusCode < 300 || res.StatusCode >= 400 || location == "" {
+			return probe
+		}
+		if hop >= linkCheckRedirects {
+			probe.err = errors.New("too many redirects")
+			return probe
+		}
+
+		next, err := res.Request.URL.Parse(location)
+		if err != nil {
+			probe.err = err
+			return probe
+		}
+		probe.chain = append(probe.chain, current)
+		current = next.String()
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
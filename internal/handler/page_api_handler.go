@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MrBista/The-Crawler/internal/diff"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type PageApiHandler struct {
+	repo        repository.CrawlRepository
+	versionRepo repository.PageVersionRepository
+}
+
+func NewPageApiHandler(repo repository.CrawlRepository, versionRepo repository.PageVersionRepository) *PageApiHandler {
+	return &PageApiHandler{
+		repo:        repo,
+		versionRepo: versionRepo,
+	}
+}
+
+// findIdentity accepts the id of a page identity or of any crawl page of it.
+func (h *PageApiHandler) findIdentity(id string) (*models.PageIdentity, error) {
+	identity, err := h.versionRepo.FindPageIdentityByID(id)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return identity, err
+	}
+
+	page, err := h.repo.FindPageByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if page.PageIdentityID == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return h.versionRepo.FindPageIdentityByID(*page.PageIdentityID)
+}
+
+func (h *PageApiHandler) GetVersions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	page, limit := parsePagination(c)
+
+	identity, err := h.findIdentity(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "page not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find page %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get page versions",
+		})
+	}
+
+	versions, total, err := h.versionRepo.FindVersions(identity.ID, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find versions of page %s: %v", identity.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get page versions",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{
+			"page":     identity,
+			"versions": versions,
+		},
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetDiff compares two versions given by ?from= and ?to=. Without them the
+// latest version is compared with the one before it.
+func (h *PageApiHandler) GetDiff(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	identity, err := h.findIdentity(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "page not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find page %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get page diff",
+		})
+	}
+
+	to := c.QueryInt("to", identity.LatestVersion)
+	from := c.QueryInt("from", to-1)
+	if from < 1 || to < 1 || from == to {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "from and to must be two different versions",
+		})
+	}
+
+	var toVersion *models.PageVersion
+	fromVersion, err := h.versionRepo.FindVersion(identity.ID, from)
+	if err == nil {
+		toVersion, err = h.versionRepo.FindVersion(identity.ID, to)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "page version not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find versions of page %s: %v", identity.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get page diff",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": versionDiff(fromVersion, toVersion),
+	})
+}
+
+// versionDiff reuses the diff stored with a version when it is compared with
+// the version right before it.
+func versionDiff(from, to *models.PageVersion) fiber.Map {
+	result := fiber.Map{
+		"from": fiber.Map{"version": from.Version, "crawl_page_id": from.CrawlPageID, "created_at": from.CreatedAt},
+		"to":   fiber.Map{"version": to.Version, "crawl_page_id": to.CrawlPageID, "created_at": to.CreatedAt},
+	}
+
+	if to.Version == from.Version+1 {
+		result["text_diff"] = to.TextDiff
+		result["lines_added"] = to.LinesAdded
+		result["lines_removed"] = to.LinesRemoved
+		result["field_changes"] = to.FieldChanges
+		return result
+	}
+
+	edits := diff.Lines(from.Text, to.Text)
+	added, removed := diff.Stats(edits)
+	result["text_diff"] = diff.Format(edits, diffContext)
+	result["lines_added"] = added
+	result["lines_removed"] = removed
+	result["field_changes"] = diff.Fields(from.ParsedData, to.ParsedData)
+	return result
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+
+	"github.com/MrBista/The-Crawler/internal/diff"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/google/uuid"
+)
+
+// diffContext adalah jumlah baris konteks di sekitar tiap hunk diff teks
+const diffContext = 3
+
+// savePage adds the page to the version history of its canonical URL and
+// extraction options, points it at the version it belongs to and saves it,
+// all in one transaction. text is what changes are measured on; documents
+// without text fall back to the raw content hash.
+func (h *CrawlHandler) savePage(page *models.CrawlPage, text string) error {
+	hash := versionHash(text, page.ParsedData, page.ContentHash)
+
+	_, version, err := h.versionRepo.RecordCrawl(page, func(previous *models.PageVersion) *models.PageVersion {
+		if previous != nil && previous.ContentHash == hash {
+			return nil
+		}
+
+		version := &models.PageVersion{
+			ID:          uuid.New().String(),
+			CrawlPageID: page.ID,
+			SessionID:   page.SessionID,
+			ContentHash: hash,
+			Title:       page.Title,
+			Text:        text,
+			ParsedData:  page.ParsedData,
+		}
+		if previous != nil {
+			edits := diff.Lines(previous.Text, text)
+			version.TextDiff = diff.Format(edits, diffContext)
+			version.LinesAdded, version.LinesRemoved = diff.Stats(edits)
+			version.FieldChanges = diff.Fields(previous.ParsedData, page.ParsedData)
+		}
+		return version
+	})
+	if err != nil {
+		log.Printf("[VERSION_ERROR] failed to save page %s with its version: %v", page.CanonicalURL, err)
+		return err
+	}
+
+	if version != nil && version.CrawlPageID == page.ID {
+		log.Printf("[VERSION] %s is now at version %d", page.CanonicalURL, version.Version)
+	}
+	return nil
+}
+
+func versionHash(text string, parsed models.JSONB, contentHash string) string {
+	if text == "" && len(parsed) == 0 {
+		return contentHash
+	}
+	// json.Marshal mengurutkan key map, jadi hasilnya stabil
+	encoded, _ := json.Marshal(parsed)
+	sum := sha256.New()
+	sum.Write([]byte(text))
+	sum.Write([]byte{0})
+	sum.Write(encoded)
+	return hex.EncodeToString(sum.Sum(nil))
+}
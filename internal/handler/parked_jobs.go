@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+)
+
+// requeueParkedJob publishes a parked job again after its session resumed.
+func requeueParkedJob(jobRepo repository.JobRepository, producer *queue.Producer, topic string, parked models.ParkedJob) error {
+	var job models.CrawlJob
+	if err := json.Unmarshal([]byte(parked.Payload), &job); err != nil {
+		return fmt.Errorf("invalid parked payload: %w", err)
+	}
+	job.NotBefore = nil
+
+	if err := jobRepo.TransitionJob(job.ID, models.JobStatusQueued, repository.JobTransition{}); err != nil {
+		return err
+	}
+
+	payload, _ := json.Marshal(job)
+	if _, _, err := producer.PublishMessage(topic, job.ID, string(payload)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cancelParkedJob finishes a parked job of a cancelled session.
+func cancelParkedJob(jobRepo repository.JobRepository, sessionRepo repository.SessionRepository, parked models.ParkedJob) error {
+	transition := repository.JobTransition{FailureReason: "session cancelled"}
+	if err := jobRepo.TransitionJob(parked.JobID, models.JobStatusCancelled, transition); err != nil {
+		return err
+	}
+	return sessionRepo.RecordResult(parked.SessionID, models.JobStatusCancelled, 0)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"gorm.io/gorm"
+)
+
+// previousPage looks up the last crawl of the job URL so the request can be
+// made conditional. A lookup error only costs a full download.
+func (h *CrawlHandler) previousPage(job models.CrawlJob) *models.CrawlPage {
+	previous, err := h.repo.FindLatestPage(job.Url, extractHash(job), job.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[RECRAWL] failed to find previous crawl of %s: %v", job.Url, err)
+		}
+		return nil
+	}
+	return previous
+}
+
+func setValidators(req *http.Request, previous *models.CrawlPage) {
+	if previous == nil {
+		return
+	}
+	if previous.ETag != "" {
+		req.Header.Set("If-None-Match", previous.ETag)
+	}
+	if previous.LastModified != "" {
+		req.Header.Set("If-Modified-Since", previous.LastModified)
+	}
+}
+
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// extractHash fingerprints the job options that shape what is stored for a
+// page: the selectors or schema and whether assets are collected as links. It
+// is empty for the default options, so pages saved before the hash existed
+// still match a plain recrawl.
+func extractHash(job models.CrawlJob) string {
+	linkCheck := job.Mode == models.CrawlModeLinkCheck
+	if len(job.Selectors) == 0 && len(job.Schema) == 0 && !linkCheck {
+		return ""
+	}
+	encoded, _ := json.Marshal(struct {
+		Selectors []string              `json:"selectors"`
+		Schema    []models.ExtractField `json:"schema"`
+		LinkCheck bool                  `json:"link_check"`
+	}{job.Selectors, job.Schema, linkCheck})
+	return contentHash(encoded)
+}
+
+// recordUnchanged handles a 304: without a body the page of this session
+// reuses the files and extracted data of previous, which was extracted with
+// the same options. The link graph is copied too, so children are discovered
+// the same way a full crawl would.
+func (h *CrawlHandler) recordUnchanged(ctx context.Context, job models.CrawlJob, previous *models.CrawlPage, requestedUrl string, hops models.RedirectChain, res *http.Response) error {
+	log.Printf("[RECRAWL] %s unchanged since %s (status %d)", job.Url, previous.CreatedAt.Format(time.RFC3339), res.StatusCode)
+
+	var parentIdPtr *string
+	if job.ParentId != "" {
+		parentIdPtr = &job.ParentId
+	}
+
+	pageRecord := *previous
+	pageRecord.ID = job.ID
+	pageRecord.ParentID = parentIdPtr
+	pageRecord.SessionID = job.SessionId
+	pageRecord.URL = requestedUrl
+	pageRecord.FinalURL = job.Url
+	pageRecord.RedirectChain = hops
+	pageRecord.Status = models.PageStatusUnchanged
+	pageRecord.DepthLevel = job.Depth
+	pageRecord.CreatedAt = time.Now()
+	pageRecord.SitemapURL, pageRecord.SitemapLastmod, pageRecord.SitemapPriority = "", nil, nil
+	pageRecord.FeedURL, pageRecord.FeedGUID, pageRecord.FeedItemTitle, pageRecord.FeedAuthor, pageRecord.FeedPublishedAt = "", "", "", "", nil
+	// 304 boleh membawa validator baru, selain itu pakai yang lama
+	if etag := res.Header.Get("ETag"); etag != "" {
+		pageRecord.ETag = etag
+	}
+	if lastModified := res.Header.Get("Last-Modified"); lastModified != "" {
+		pageRecord.LastModified = lastModified
+	}
+
+	withSitemapSource(&pageRecord, job)
+	withFeedItem(&pageRecord, job)
+
+	// Isi sama dengan versi terakhir, cukup catat waktu crawl-nya
+	var err error
+	if previous.PageIdentityID != nil {
+		_, _, err = h.versionRepo.RecordCrawl(&pageRecord, func(*models.PageVersion) *models.PageVersion {
+			return nil
+		})
+	} else {
+		err = h.repo.SavePage(&pageRecord)
+	}
+	if err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save unchanged page: %v", err)
+		return retryableError("failed to save page", err).withResponse(res.StatusCode, 0)
+	}
+
+	// Watch yang belum punya baseline harus tetap mencatatnya walaupun isinya tidak berubah
+	if job.WatchID != "" {
+		if err := h.evaluateWatch(ctx, job, &pageRecord); err != nil {
+			log.Printf("[WATCH_ERROR] failed to evaluate watch %s: %v", job.WatchID, err)
+			return retryableError("failed to evaluate watch", err).withResponse(res.StatusCode, 0)
+		}
+	}
+
+	previousLinks, err := h.linkRepo.FindAllOutlinks(previous.ID)
+	if err != nil {
+		return retryableError("failed to load previous links", err).withResponse(res.StatusCode, 0)
+	}
+
+	now := time.Now()
+	links := make([]models.PageLink, 0, len(previousLinks))
+	hrefs := make([]string, 0, len(previousLinks))
+	for _, link := range previousLinks {
+		// Crawl biasa hanya mencatat link, aset dari sesi link_check tidak ikut disalin
+		if link.Kind != models.LinkKindLink && job.Mode != models.CrawlModeLinkCheck {
+			continue
+		}
+		link.ID = 0
+		link.SessionID = job.SessionId
+		link.SourcePageID = job.ID
+		link.SourceURL = job.Url
+		link.CreatedAt = now
+		link.Position = len(links)
+		links = append(links, link)
+		if link.Kind == models.LinkKindLink {
+			hrefs = append(hrefs, link.TargetURL)
+		}
+	}
+
+	if err := h.linkRepo.ReplaceLinks(job.ID, links); err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save page links")
+		return retryableError("failed to save links", err).withResponse(res.StatusCode, 0)
+	}
+
+	if job.Depth > 0 {
+		h.enqueueLinks(job, hrefs)
+	}
+	if job.Mode == models.CrawlModeLinkCheck {
+		h.enqueueLinkChecks(job, links)
+	}
+
+	h.finishJob(job, models.JobStatusSucceeded, repository.JobTransition{
+		HTTPStatus: res.StatusCode,
+	})
+	return nil
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/scope"
+)
+
+const maxRedirects = 10
+
+var (
+	errRedirectLoop     = errors.New("redirect loop")
+	errTooManyRedirects = errors.New("too many redirects")
+)
+
+// redirectTracker collects the hops of one fetch. stopReason is set when a
+// redirect was recorded but not followed.
+type redirectTracker struct {
+	hops       models.RedirectChain
+	stopReason string
+}
+
+// checkRedirect records every hop and applies the job's redirect policy, the
+// crawl scope and robots.txt to each target. matcher is nil for root jobs, a
+// root URL that redirects (http to https, bare domain to www) is always
+// allowed to land.
+func (h *CrawlHandler) checkRedirect(job models.CrawlJob, matcher *scope.Matcher, tracker *redirectTracker) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		previous := via[len(via)-1]
+		hop := models.RedirectHop{
+			URL:      previous.URL.String(),
+			Location: req.URL.String(),
+		}
+		if req.Response != nil {
+			hop.StatusCode = req.Response.StatusCode
+		}
+		tracker.hops = append(tracker.hops, hop)
+
+		for _, visited := range via {
+			if visited.URL.String() == req.URL.String() {
+				return errRedirectLoop
+			}
+		}
+		if len(via) >= maxRedirects {
+			return errTooManyRedirects
+		}
+
+		switch job.RedirectPolicy {
+		case models.RedirectNone:
+			tracker.stopReason = "redirect policy is none"
+		case models.RedirectSameHost:
+			if !strings.EqualFold(req.URL.Hostname(), via[0].URL.Hostname()) {
+				tracker.stopReason = "redirect leaves host " + via[0].URL.Hostname()
+			}
+		}
+		if tracker.stopReason == "" && matcher != nil && !matcher.Allows(req.URL) {
+			tracker.stopReason = "redirect target out of scope"
+		}
+		if tracker.stopReason == "" {
+			// Error membatalkan request, job di-retry seperti gagal fetch biasa
+			allowed, err := h.robots.Check(req.URL.String())
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				tracker.stopReason = "redirect target disallowed by robots.txt"
+			}
+		}
+
+		if tracker.stopReason != "" {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+// recordRedirect saves a page without content for a redirect that was not
+// followed and skips the job.
+func (h *CrawlHandler) recordRedirect(job models.CrawlJob, res *http.Response, tracker *redirectTracker) error {
+	location := ""
+	if len(tracker.hops) > 0 {
+		location = tracker.hops[len(tracker.hops)-1].Location
+	}
+	if canonicalUrl, err := h.canonicalizer.Canonicalize(location); err == nil {
+		location = canonicalUrl
+	}
+
+	var parentIdPtr *string
+	if job.ParentId != "" {
+		parentIdPtr = &job.ParentId
+	}
+
+	pageRecord := models.CrawlPage{
+		ID:            job.ID,
+		ParentID:      parentIdPtr,
+		SessionID:     job.SessionId,
+		URL:           job.Url,
+		CanonicalURL:  job.Url,
+		FinalURL:      location,
+		RedirectChain: tracker.hops,
+		Status:        models.PageStatusRedirected,
+		DepthLevel:    job.Depth,
+		CreatedAt:     time.Now(),
+	}
+	withSitemapSource(&pageRecord, job)
+	withFeedItem(&pageRecord, job)
+	if err := h.repo.SavePage(&pageRecord); err != nil {
+		log.Printf("[PROCESS_CRAWL_ERROR] failed to save redirected page")
+		return retryableError("failed to save page", err).withResponse(res.StatusCode, 0)
+	}
+
+	log.Printf("[REDIRECT] %s -> %s not followed: %s", job.Url, location, tracker.stopReason)
+	h.finishJob(job, models.JobStatusSkipped, repository.JobTransition{
+		FailureReason: "redirect not followed: " + tracker.stopReason,
+		HTTPStatus:    res.StatusCode,
+	})
+	return nil
+}
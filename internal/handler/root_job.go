@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/dedup"
+	"github.com/MrBista/The-Crawler/internal/extract"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/scope"
+)
+
+// validateCrawlRequest checks the options of a crawl request whose URL has
+// already been canonicalized. The error message is meant for the API caller.
+func validateCrawlRequest(canonicalUrl string, req models.CrawlJob) error {
+	if _, err := scope.Compile(canonicalUrl, req.Scope); err != nil {
+		return err
+	}
+
+	switch req.Mode {
+	case "", models.CrawlModeCrawl, models.CrawlModeLinkCheck, models.CrawlModeSitemap, models.CrawlModeFeed:
+	default:
+		return fmt.Errorf("unknown crawl mode %q", req.Mode)
+	}
+
+	switch req.RedirectPolicy {
+	case "", models.RedirectFollow, models.RedirectSameHost, models.RedirectNone:
+	default:
+		return fmt.Errorf("unknown redirect_policy %q", req.RedirectPolicy)
+	}
+
+	switch req.BinaryPolicy {
+	case "", models.BinaryPolicySkip, models.BinaryPolicyStore:
+	default:
+		return fmt.Errorf("unknown binary_policy %q", req.BinaryPolicy)
+	}
+
+	if _, err := extract.Compile(req.Schema); err != nil {
+		return err
+	}
+
+	if budget := req.Budget; budget != nil {
+		if budget.MaxPages < 0 || budget.MaxBytes < 0 || budget.MaxPagesPerHost < 0 || budget.MaxDurationSeconds < 0 {
+			return errors.New("budget values must not be negative")
+		}
+	}
+	return nil
+}
+
+// newRootJob builds the first job of a session from the options of a crawl
+// request.
+func newRootJob(jobId, canonicalUrl string, req models.CrawlJob) models.CrawlJob {
+	return models.CrawlJob{
+		ID:        jobId,
+		SessionId: jobId,
+		Url:       canonicalUrl,
+		Depth:     req.Depth,
+		Selectors: req.Selectors,
+		Schema:    req.Schema,
+		RootUrl:   canonicalUrl,
+		Scope:     req.Scope,
+		Mode:      req.Mode,
+		// Policy redirect berlaku untuk seluruh session
+		RedirectPolicy: req.RedirectPolicy,
+		SitemapSince:   req.SitemapSince,
+		BinaryPolicy:   req.BinaryPolicy,
+	}
+}
+
+// newRootSession builds the session of a root job. MaxDurationSeconds counts
+// from now, so a recurring crawl gets a fresh deadline on every run.
+func newRootSession(job models.CrawlJob, budget *models.CrawlBudget) models.CrawlSession {
+	// Root job sekaligus menjadi id session untuk seluruh turunannya
+	session := models.CrawlSession{
+		ID:          job.ID,
+		RootURL:     job.Url,
+		PagesQueued: 1,
+	}
+	if budget == nil {
+		return session
+	}
+
+	session.MaxPages = budget.MaxPages
+	session.MaxBytes = budget.MaxBytes
+	session.MaxPagesPerHost = budget.MaxPagesPerHost
+	session.Deadline = budget.Deadline
+	if budget.MaxDurationSeconds > 0 {
+		deadline := time.Now().Add(time.Duration(budget.MaxDurationSeconds) * time.Second)
+		if session.Deadline == nil || deadline.Before(*session.Deadline) {
+			session.Deadline = &deadline
+		}
+	}
+	return session
+}
+
+// startRootJob creates the session of a root job, records the job and
+// publishes it. It is shared by crawls submitted through the API and those
+// the service starts on its own for schedules and watches.
+func startRootJob(sessionRepo repository.SessionRepository, jobRepo repository.JobRepository, visited dedup.VisitedSet, producer *queue.Producer, topic string, job models.CrawlJob, session models.CrawlSession) error {
+	if err := sessionRepo.CreateSession(&session); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if _, err := visited.MarkVisited(job.SessionId, job.Url); err != nil {
+		log.Printf("[ROOT_JOB_ERROR] failed to mark root url visited %s: %v", job.Url, err)
+	}
+
+	jobRecord := models.CrawlJobRecord{
+		ID:        job.ID,
+		SessionID: job.SessionId,
+		URL:       job.Url,
+		Depth:     job.Depth,
+	}
+	if err := jobRepo.CreateJob(&jobRecord); err != nil {
+		return fmt.Errorf("failed to record job: %w", err)
+	}
+
+	payload, _ := json.Marshal(job)
+	partition, offset, err := producer.PublishMessage(topic, job.ID, string(payload))
+	if err != nil {
+		// Session tidak boleh menggantung menunggu job yang tidak pernah terkirim
+		transition := repository.JobTransition{FailureReason: fmt.Sprintf("failed to publish job: %v", err)}
+		if err := jobRepo.TransitionJob(job.ID, models.JobStatusFailed, transition); err != nil {
+			log.Printf("[ROOT_JOB_ERROR] failed to mark job %s as failed: %v", job.ID, err)
+		} else if err := sessionRepo.RecordResult(job.SessionId, models.JobStatusFailed, 0); err != nil {
+			log.Printf("[ROOT_JOB_ERROR] failed to record job %s in session: %v", job.ID, err)
+		}
+		return fmt.Errorf("failed to publish job: %w", err)
+	}
+
+	log.Printf("Message stored in topic (%s) with partion = %v and offset = %v", topic, partition, offset)
+	return nil
+}
+
+// sessionActive reports whether a session started earlier is still running
+// or paused. An empty or unknown id counts as finished.
+func sessionActive(sessionRepo repository.SessionRepository, sessionId string) bool {
+	if sessionId == "" {
+		return false
+	}
+	session, err := sessionRepo.FindSessionByID(sessionId)
+	if err != nil {
+		return false
+	}
+	return session.Status == models.SessionStatusRunning || session.Status == models.SessionStatusPaused
+}
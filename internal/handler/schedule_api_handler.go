@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/canonical"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/schedule"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ScheduleApiHandler struct {
+	scheduleRepo  repository.ScheduleRepository
+	canonicalizer *canonical.Canonicalizer
+}
+
+func NewScheduleApiHandler(scheduleRepo repository.ScheduleRepository, canonicalizer *canonical.Canonicalizer) *ScheduleApiHandler {
+	return &ScheduleApiHandler{
+		scheduleRepo:  scheduleRepo,
+		canonicalizer: canonicalizer,
+	}
+}
+
+// CreateSchedule registers a recurring crawl. The template is validated like
+// a body of POST /api/v1/crawl, the first run happens at the next cron match
+// or one interval from now.
+func (h *ScheduleApiHandler) CreateSchedule(c *fiber.Ctx) error {
+	var reqBody models.CrawlSchedule
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to parse body",
+		})
+	}
+
+	template := models.CrawlJob(reqBody.Template)
+	if template.Url == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "template.url is required",
+		})
+	}
+	canonicalUrl, err := h.canonicalizer.Canonicalize(template.Url)
+	if err != nil || !strings.HasPrefix(canonicalUrl, "http") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "template.url must be an absolute http or https url",
+		})
+	}
+	if err := validateCrawlRequest(canonicalUrl, template); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": err.Error(),
+		})
+	}
+	// Deadline absolut sudah lewat sejak run kedua, schedule memakai durasi relatif
+	if template.Budget != nil && template.Budget.Deadline != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "template.budget.deadline is not supported in schedules, use max_duration_seconds",
+		})
+	}
+
+	now := time.Now()
+	s := models.CrawlSchedule{
+		ID:              uuid.New().String(),
+		Name:            reqBody.Name,
+		Cron:            strings.TrimSpace(reqBody.Cron),
+		IntervalSeconds: reqBody.IntervalSeconds,
+		TimeZone:        reqBody.TimeZone,
+		JitterSeconds:   reqBody.JitterSeconds,
+		OverlapPolicy:   reqBody.OverlapPolicy,
+		Active:          true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := schedule.Validate(&s); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": err.Error(),
+		})
+	}
+
+	// Hanya opsi request yang disimpan, field runtime job diisi ulang setiap run
+	stored := newRootJob("", canonicalUrl, template)
+	stored.Budget = template.Budget
+	s.Template = models.CrawlTemplate(stored)
+
+	if s.NextRunAt, err = schedule.Next(s, now); err != nil {
+		log.Printf("[API_ERROR] failed to compute first run of schedule: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to create schedule",
+		})
+	}
+
+	if err := h.scheduleRepo.CreateSchedule(&s); err != nil {
+		log.Printf("[API_ERROR] failed to create schedule: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to create schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data":    s,
+		"message": "schedule created",
+	})
+}
+
+func (h *ScheduleApiHandler) ListSchedules(c *fiber.Ctx) error {
+	page, limit := parsePagination(c)
+
+	schedules, total, err := h.scheduleRepo.FindSchedules(limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find schedules: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get schedules",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": schedules,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *ScheduleApiHandler) GetSchedule(c *fiber.Ctx) error {
+	scheduleId := c.Params("id")
+
+	s, err := h.scheduleRepo.FindScheduleByID(scheduleId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "schedule not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find schedule %s: %v", scheduleId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": s,
+	})
+}
+
+func (h *ScheduleApiHandler) DeleteSchedule(c *fiber.Ctx) error {
+	scheduleId := c.Params("id")
+
+	err := h.scheduleRepo.DeleteSchedule(scheduleId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "schedule not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to delete schedule %s: %v", scheduleId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to delete schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    fiber.Map{"id": scheduleId},
+		"message": "schedule deleted",
+	})
+}
+
+func (h *ScheduleApiHandler) PauseSchedule(c *fiber.Ctx) error {
+	scheduleId := c.Params("id")
+
+	err := h.scheduleRepo.SetScheduleActive(scheduleId, false, nil)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "schedule not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to pause schedule %s: %v", scheduleId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to pause schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    fiber.Map{"id": scheduleId, "active": false},
+		"message": "schedule paused",
+	})
+}
+
+// ResumeSchedule reactivates a schedule. Runs missed while it was paused are
+// not made up, the next run is computed from now.
+func (h *ScheduleApiHandler) ResumeSchedule(c *fiber.Ctx) error {
+	scheduleId := c.Params("id")
+
+	s, err := h.scheduleRepo.FindScheduleByID(scheduleId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "schedule not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find schedule %s: %v", scheduleId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to resume schedule",
+		})
+	}
+
+	next, err := schedule.Next(*s, time.Now())
+	if err != nil || next.IsZero() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"data":    nil,
+			"message": "schedule has no run left",
+		})
+	}
+
+	if err := h.scheduleRepo.SetScheduleActive(scheduleId, true, &next); err != nil {
+		log.Printf("[API_ERROR] failed to resume schedule %s: %v", scheduleId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to resume schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    fiber.Map{"id": scheduleId, "active": true, "next_run_at": next},
+		"message": "schedule resumed",
+	})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/dedup"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/schedule"
+	"github.com/google/uuid"
+)
+
+const (
+	// Cron paling halus per menit, polling lebih sering agar run tidak telat jauh
+	schedulePollInterval = 15 * time.Second
+	scheduleBatchSize    = 50
+)
+
+// ScheduleRunner starts the crawls of due schedules. Every API replica can
+// run one, a run is claimed by a single replica through row locking.
+type ScheduleRunner struct {
+	scheduleRepo repository.ScheduleRepository
+	jobRepo      repository.JobRepository
+	sessionRepo  repository.SessionRepository
+	visited      dedup.VisitedSet
+	producer     *queue.Producer
+	kafkaTopic   string
+}
+
+func NewScheduleRunner(scheduleRepo repository.ScheduleRepository, jobRepo repository.JobRepository, sessionRepo repository.SessionRepository, visited dedup.VisitedSet, producer *queue.Producer, topic string) *ScheduleRunner {
+	return &ScheduleRunner{
+		scheduleRepo: scheduleRepo,
+		jobRepo:      jobRepo,
+		sessionRepo:  sessionRepo,
+		visited:      visited,
+		producer:     producer,
+		kafkaTopic:   topic,
+	}
+}
+
+// Run polls until ctx is cancelled.
+func (r *ScheduleRunner) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulePollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.runDueSchedules()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ScheduleRunner) runDueSchedules() {
+	// Run yang terlewat saat semua replica mati hanya dijalankan sekali, tidak dikejar satu per satu
+	schedules, err := r.scheduleRepo.ClaimDueSchedules(scheduleBatchSize, nextScheduleRun)
+	if err != nil {
+		log.Printf("[SCHEDULE_ERROR] failed to claim due schedules: %v", err)
+		return
+	}
+
+	for _, s := range schedules {
+		if s.OverlapPolicy != models.OverlapAllow && sessionActive(r.sessionRepo, s.LastSessionID) {
+			log.Printf("[SCHEDULE] skip schedule %s, session %s is still running", s.ID, s.LastSessionID)
+			r.recordRun(s.ID, "", models.ScheduleRunSkipped, "")
+			continue
+		}
+
+		template := models.CrawlJob(s.Template)
+		jobId := uuid.New().String()
+		job := newRootJob(jobId, template.Url, template)
+		session := newRootSession(job, template.Budget)
+
+		if err := startRootJob(r.sessionRepo, r.jobRepo, r.visited, r.producer, r.kafkaTopic, job, session); err != nil {
+			log.Printf("[SCHEDULE_ERROR] failed to start crawl of schedule %s: %v", s.ID, err)
+			r.recordRun(s.ID, "", models.ScheduleRunFailed, err.Error())
+			continue
+		}
+		r.recordRun(s.ID, jobId, models.ScheduleRunStarted, "")
+		log.Printf("[SCHEDULE] started session %s for schedule %s", jobId, s.ID)
+	}
+}
+
+func (r *ScheduleRunner) recordRun(id, sessionId, status, runErr string) {
+	if err := r.scheduleRepo.RecordScheduleRun(id, sessionId, status, runErr); err != nil {
+		log.Printf("[SCHEDULE_ERROR] failed to record run of schedule %s: %v", id, err)
+	}
+}
+
+// nextScheduleRun is counted from now rather than from the due time, so a
+// run that started late does not make the next one fire right away.
+func nextScheduleRun(s models.CrawlSchedule) time.Time {
+	next, err := schedule.Next(s, time.Now())
+	if err != nil {
+		log.Printf("[SCHEDULE_ERROR] failed to compute next run of schedule %s: %v", s.ID, err)
+		return time.Time{}
+	}
+	if next.IsZero() {
+		log.Printf("[SCHEDULE] schedule %s has no run left, deactivating", s.ID)
+	}
+	return next
+}
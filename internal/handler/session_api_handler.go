@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type SessionApiHandler struct {
+	sessionRepo   repository.SessionRepository
+	linkRepo      repository.LinkRepository
+	linkCheckRepo repository.LinkCheckRepository
+}
+
+func NewSessionApiHandler(sessionRepo repository.SessionRepository, linkRepo repository.LinkRepository, linkCheckRepo repository.LinkCheckRepository) *SessionApiHandler {
+	return &SessionApiHandler{
+		sessionRepo:   sessionRepo,
+		linkRepo:      linkRepo,
+		linkCheckRepo: linkCheckRepo,
+	}
+}
+
+func (h *SessionApiHandler) GetSession(c *fiber.Ctx) error {
+	sessionId := c.Params("id")
+
+	session, err := h.sessionRepo.FindSessionByID(sessionId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "crawl session not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find session %s: %v", sessionId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get crawl session",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{
+			"session":       session,
+			"pages_pending": session.PagesPending(),
+			"finished":      session.Status != models.SessionStatusRunning && session.PagesPending() == 0,
+		},
+	})
+}
+
+// GetBrokenLinks reports the broken URLs of a link_check session, each with the
+// pages that reference it.
+func (h *SessionApiHandler) GetBrokenLinks(c *fiber.Ctx) error {
+	sessionId := c.Params("id")
+	page, limit := parsePagination(c)
+
+	checks, total, err := h.linkCheckRepo.FindBrokenLinks(sessionId, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find broken links of session %s: %v", sessionId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get broken links",
+		})
+	}
+
+	urls := make([]string, 0, len(checks))
+	for _, check := range checks {
+		urls = append(urls, check.URL)
+	}
+
+	referrers, err := h.linkRepo.FindReferrers(sessionId, urls)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find referrers of session %s: %v", sessionId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get broken links",
+		})
+	}
+
+	referrersByUrl := make(map[string][]fiber.Map)
+	for _, link := range referrers {
+		referrersByUrl[link.TargetURL] = append(referrersByUrl[link.TargetURL], fiber.Map{
+			"page_id":     link.SourcePageID,
+			"page_url":    link.SourceURL,
+			"anchor_text": link.AnchorText,
+			"kind":        link.Kind,
+			"position":    link.Position,
+		})
+	}
+
+	report := make([]fiber.Map, 0, len(checks))
+	for _, check := range checks {
+		pages := referrersByUrl[check.URL]
+		if pages == nil {
+			pages = []fiber.Map{}
+		}
+		report = append(report, fiber.Map{
+			"check":      check,
+			"referenced": pages,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": report,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/scope"
+	"github.com/MrBista/The-Crawler/internal/sitemap"
+)
+
+// seedFromSitemaps enqueues the in-scope URLs listed in the sitemaps of the
+// root host, highest priority first so a page budget keeps the important
+// ones. Children are plain crawl jobs at the root's depth, sitemap seeding
+// does not use up a level. Only a context error is returned.
+func (h *CrawlHandler) seedFromSitemaps(ctx context.Context, job models.CrawlJob) error {
+	root, err := url.Parse(job.Url)
+	if err != nil {
+		return nil
+	}
+
+	rootUrl := job.RootUrl
+	if rootUrl == "" {
+		rootUrl = job.Url
+	}
+	matcher, err := scope.Compile(rootUrl, job.Scope)
+	if err != nil {
+		log.Printf("[SITEMAP_ERROR] invalid scope for job %s: %v", job.ID, err)
+		return nil
+	}
+
+	limit := h.sitemapLimit(job.SessionId)
+	if limit == 0 {
+		log.Printf("[SITEMAP] session %s has no page budget left, skip sitemaps of %s", job.SessionId, job.Url)
+		return nil
+	}
+
+	fetcher := sitemap.NewFetcher(h.userAgent, func(ctx context.Context, rawUrl string) (func(), error) {
+		return h.limiter.Acquire(ctx, hostOf(rawUrl), h.robots.CrawlDelay(rawUrl))
+	}, h.robots.Allowed)
+
+	candidates := sitemap.Candidates(job.Url, h.robots.Rules(root).Sitemaps)
+	entries, errs := fetcher.Collect(ctx, candidates, job.SitemapSince, limit)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	for _, err := range errs {
+		log.Printf("[SITEMAP_ERROR] %v", err)
+	}
+
+	seeded := 0
+	for _, entry := range entries {
+		entryUrl, err := h.canonicalizer.Canonicalize(entry.Loc)
+		if err != nil || !strings.HasPrefix(entryUrl, "http") {
+			continue
+		}
+
+		entryUrl, inScope := matcher.Apply(entryUrl)
+		if !inScope {
+			continue
+		}
+
+		added, err := h.visited.MarkVisited(job.SessionId, entryUrl)
+		if err != nil {
+			log.Printf("[ERROR] failed to check visited url %s: %v", entryUrl, err)
+			continue
+		}
+		if !added {
+			continue
+		}
+
+		childJob := h.newChildJob(job, entryUrl, job.Depth)
+		childJob.Mode = models.CrawlModeCrawl
+		childJob.Sitemap = &models.SitemapSource{
+			URL:        entry.Source,
+			Lastmod:    entry.Lastmod,
+			Priority:   entry.Priority,
+			ChangeFreq: entry.ChangeFreq,
+		}
+		enqueued, more := h.enqueueChild(job, childJob)
+		if enqueued {
+			seeded++
+		}
+		if !more {
+			break
+		}
+	}
+
+	log.Printf("[SITEMAP] seeded %d of %d entries from %d sitemap candidates for %s", seeded, len(entries), len(candidates), job.Url)
+	return nil
+}
+
+// sitemapLimit is how many sitemap entries are worth collecting for the
+// session: the pages left in its budget, or sitemap.MaxEntries without one.
+// Zero means the budget is already used up.
+// Entries dropped later by scope or the visited set are not made up for.
+func (h *CrawlHandler) sitemapLimit(sessionId string) int {
+	session, err := h.sessionRepo.FindSessionByID(sessionId)
+	if err != nil || session.MaxPages == 0 {
+		return sitemap.MaxEntries
+	}
+	left := session.MaxPages - (session.PagesQueued - session.ChecksQueued)
+	if left <= 0 {
+		return 0
+	}
+	if left > sitemap.MaxEntries {
+		return sitemap.MaxEntries
+	}
+	return int(left)
+}
+
+func withSitemapSource(page *models.CrawlPage, job models.CrawlJob) {
+	if job.Sitemap == nil {
+		return
+	}
+	page.SitemapURL = job.Sitemap.URL
+	page.SitemapLastmod = job.Sitemap.Lastmod
+	page.SitemapPriority = job.Sitemap.Priority
+}
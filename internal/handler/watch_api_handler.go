@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/canonical"
+	"github.com/MrBista/The-Crawler/internal/extract"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/watch"
+	"github.com/MrBista/The-Crawler/internal/webhook"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WatchApiHandler struct {
+	watchRepo     repository.WatchRepository
+	canonicalizer *canonical.Canonicalizer
+}
+
+func NewWatchApiHandler(watchRepo repository.WatchRepository, canonicalizer *canonical.Canonicalizer) *WatchApiHandler {
+	return &WatchApiHandler{
+		watchRepo:     watchRepo,
+		canonicalizer: canonicalizer,
+	}
+}
+
+// CreateWatch registers a watch. Its first crawl only records the baseline,
+// the webhook fires from the second crawl on. The signing secret is returned
+// here and nowhere else.
+func (h *WatchApiHandler) CreateWatch(c *fiber.Ctx) error {
+	var reqBody models.Watch
+	if err := c.BodyParser(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to parse body",
+		})
+	}
+
+	if reqBody.URL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "url is required",
+		})
+	}
+	canonicalUrl, err := h.canonicalizer.Canonicalize(reqBody.URL)
+	if err != nil || !strings.HasPrefix(canonicalUrl, "http") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": "url must be an absolute http or https url",
+		})
+	}
+
+	if _, err := extract.Compile(reqBody.Schema); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": err.Error(),
+		})
+	}
+	if err := watch.Validate(&reqBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"data":    nil,
+			"message": err.Error(),
+		})
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		log.Printf("[API_ERROR] failed to generate webhook secret: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to create watch",
+		})
+	}
+
+	now := time.Now()
+	w := models.Watch{
+		ID:              uuid.New().String(),
+		Name:            reqBody.Name,
+		URL:             canonicalUrl,
+		Schema:          reqBody.Schema,
+		IntervalSeconds: reqBody.IntervalSeconds,
+		Condition:       reqBody.Condition,
+		Field:           reqBody.Field,
+		Threshold:       reqBody.Threshold,
+		Direction:       reqBody.Direction,
+		Text:            reqBody.Text,
+		WebhookURL:      reqBody.WebhookURL,
+		Secret:          secret,
+		Active:          true,
+		NextRunAt:       now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := h.watchRepo.CreateWatch(&w); err != nil {
+		log.Printf("[API_ERROR] failed to create watch: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to create watch",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": fiber.Map{
+			"watch":  w,
+			"secret": w.Secret,
+		},
+		"message": "watch created",
+	})
+}
+
+func (h *WatchApiHandler) ListWatches(c *fiber.Ctx) error {
+	page, limit := parsePagination(c)
+
+	watches, total, err := h.watchRepo.FindWatches(limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find watches: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get watches",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": watches,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *WatchApiHandler) GetWatch(c *fiber.Ctx) error {
+	watchId := c.Params("id")
+
+	w, err := h.watchRepo.FindWatchByID(watchId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "watch not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find watch %s: %v", watchId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get watch",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": w,
+	})
+}
+
+func (h *WatchApiHandler) DeleteWatch(c *fiber.Ctx) error {
+	watchId := c.Params("id")
+
+	err := h.watchRepo.DeleteWatch(watchId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"data":    nil,
+			"message": "watch not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[API_ERROR] failed to delete watch %s: %v", watchId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to delete watch",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    fiber.Map{"id": watchId, "active": false},
+		"message": "watch deactivated",
+	})
+}
+
+func (h *WatchApiHandler) GetDeliveries(c *fiber.Ctx) error {
+	watchId := c.Params("id")
+	page, limit := parsePagination(c)
+
+	deliveries, total, err := h.watchRepo.FindDeliveries(watchId, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[API_ERROR] failed to find deliveries of watch %s: %v", watchId, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"data":    nil,
+			"message": "failed to get webhook deliveries",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": deliveries,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/watch"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// evaluateWatch compares the version the watch saw last with the version of
+// the page just saved and fires its webhook when the condition holds. The
+// first crawl of a watch only records the baseline.
+func (h *CrawlHandler) evaluateWatch(ctx context.Context, job models.CrawlJob, page *models.CrawlPage) error {
+	w, err := h.watchRepo.FindWatchByID(job.WatchID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[WATCH] watch %s of job %s no longer exists", job.WatchID, job.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if page.PageIdentityID == nil || page.PageVersion == 0 {
+		return nil
+	}
+
+	identityId := *page.PageIdentityID
+	from := w.LastVersion
+	// Canonical URL atau opsi ekstraksi berubah, riwayat lama tidak bisa dibandingkan
+	if w.PageIdentityID == nil || *w.PageIdentityID != identityId {
+		from = 0
+	} else if from == page.PageVersion {
+		return nil
+	}
+
+	var delivery *models.WebhookDelivery
+	if from > 0 {
+		old, err := h.versionRepo.FindVersion(identityId, from)
+		if err != nil {
+			return err
+		}
+		current, err := h.versionRepo.FindVersion(identityId, page.PageVersion)
+		if err != nil {
+			return err
+		}
+
+		result := watch.Evaluate(w, old, current)
+		if result.Fired {
+			delivery = newWatchDelivery(w, page, old, current, result)
+		}
+	}
+
+	advanced, err := h.watchRepo.AdvanceWatch(w.ID, page.PageIdentityID, w.LastVersion, page.PageVersion, delivery)
+	if err != nil {
+		return err
+	}
+	if !advanced || delivery == nil {
+		return nil
+	}
+
+	log.Printf("[WATCH] watch %s fired on %s version %d", w.ID, page.CanonicalURL, page.PageVersion)
+	deliverWebhook(ctx, h.watchRepo, h.webhookSender, delivery, w.Secret)
+	return nil
+}
+
+func newWatchDelivery(w *models.Watch, page *models.CrawlPage, old, current *models.PageVersion, result watch.Result) *models.WebhookDelivery {
+	now := time.Now()
+	deliveryId := uuid.New().String()
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"event":       webhookEvent,
+		"delivery_id": deliveryId,
+		"watch_id":    w.ID,
+		"watch_name":  w.Name,
+		"url":         page.FinalURL,
+		"condition":   w.Condition,
+		"field":       w.Field,
+		"old": map[string]interface{}{
+			"version":       old.Version,
+			"crawl_page_id": old.CrawlPageID,
+			"crawled_at":    old.CreatedAt,
+			"value":         result.Old,
+		},
+		"new": map[string]interface{}{
+			"version":       current.Version,
+			"crawl_page_id": current.CrawlPageID,
+			"crawled_at":    current.CreatedAt,
+			"value":         result.New,
+		},
+		"field_changes": result.Changes,
+		"triggered_at":  now,
+	})
+
+	return &models.WebhookDelivery{
+		ID:      deliveryId,
+		WatchID: w.ID,
+		URL:     w.WebhookURL,
+		Payload: string(payload),
+		Status:  models.DeliveryPending,
+		// Percobaan pertama dikirim langsung oleh worker, poller baru mengambil setelah ini
+		NextAttemptAt: now.Add(time.Minute),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
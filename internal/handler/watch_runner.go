@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/dedup"
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/queue"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	watchPollInterval = 15 * time.Second
+	watchBatchSize    = 50
+)
+
+// WatchRunner starts the crawls of due watches and retries failed webhook
+// deliveries. Every replica can run one, rows are claimed with SKIP LOCKED.
+type WatchRunner struct {
+	watchRepo   repository.WatchRepository
+	jobRepo     repository.JobRepository
+	sessionRepo repository.SessionRepository
+	visited     dedup.VisitedSet
+	sender      *webhook.Sender
+	producer    *queue.Producer
+	kafkaTopic  string
+}
+
+func NewWatchRunner(watchRepo repository.WatchRepository, jobRepo repository.JobRepository, sessionRepo repository.SessionRepository, visited dedup.VisitedSet, sender *webhook.Sender, producer *queue.Producer, topic string) *WatchRunner {
+	return &WatchRunner{
+		watchRepo:   watchRepo,
+		jobRepo:     jobRepo,
+		sessionRepo: sessionRepo,
+		visited:     visited,
+		sender:      sender,
+		producer:    producer,
+		kafkaTopic:  topic,
+	}
+}
+
+// Run polls until ctx is cancelled.
+func (r *WatchRunner) Run(ctx context.Context) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.runDueWatches()
+		r.retryDeliveries(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *WatchRunner) runDueWatches() {
+	watches, err := r.watchRepo.ClaimDueWatches(watchBatchSize)
+	if err != nil {
+		log.Printf("[WATCH_ERROR] failed to claim due watches: %v", err)
+		return
+	}
+
+	for _, w := range watches {
+		// Situs yang lambat tidak boleh menumpuk session dari satu watch
+		if sessionActive(r.sessionRepo, w.LastSessionID) {
+			log.Printf("[WATCH] skip watch %s, session %s is still running", w.ID, w.LastSessionID)
+			continue
+		}
+
+		jobId := uuid.New().String()
+		job := models.CrawlJob{
+			ID:        jobId,
+			SessionId: jobId,
+			Url:       w.URL,
+			Schema:    w.Schema,
+			RootUrl:   w.URL,
+			Mode:      models.CrawlModeCrawl,
+			WatchID:   w.ID,
+		}
+		session := models.CrawlSession{
+			ID:          jobId,
+			RootURL:     w.URL,
+			PagesQueued: 1,
+		}
+
+		if err := startRootJob(r.sessionRepo, r.jobRepo, r.visited, r.producer, r.kafkaTopic, job, session); err != nil {
+			log.Printf("[WATCH_ERROR] failed to start crawl of watch %s: %v", w.ID, err)
+			continue
+		}
+		if err := r.watchRepo.SetWatchSession(w.ID, jobId); err != nil {
+			log.Printf("[WATCH_ERROR] failed to record session of watch %s: %v", w.ID, err)
+		}
+		log.Printf("[WATCH] started session %s for watch %s", jobId, w.ID)
+	}
+}
+
+func (r *WatchRunner) retryDeliveries(ctx context.Context) {
+	deliveries, err := r.watchRepo.ClaimDueDeliveries(watchBatchSize)
+	if err != nil {
+		log.Printf("[WEBHOOK_ERROR] failed to claim due deliveries: %v", err)
+		return
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		w, err := r.watchRepo.FindWatchByID(delivery.WatchID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[WEBHOOK] watch of delivery %s no longer exists", delivery.ID)
+			continue
+		}
+		if err != nil {
+			log.Printf("[WEBHOOK_ERROR] failed to find watch of delivery %s: %v", delivery.ID, err)
+			continue
+		}
+		deliverWebhook(ctx, r.watchRepo, r.sender, delivery, w.Secret)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/webhook"
+)
+
+const (
+	webhookEvent        = "watch.triggered"
+	maxDeliveryAttempts = 8
+	deliveryBaseDelay   = 30 * time.Second
+	deliveryMaxDelay    = 6 * time.Hour
+)
+
+// deliveryBackoff doubles the wait after every failed attempt: 30s, 1m, 2m,
+// ... capped at 6h.
+func deliveryBackoff(attempt int) time.Duration {
+	delay := deliveryBaseDelay
+	for i := 1; i < attempt && delay < deliveryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, deliveryMaxDelay)
+}
+
+// deliverWebhook makes one attempt of delivery and persists the outcome. The
+// delivery is marked failed once maxDeliveryAttempts are used up.
+func deliverWebhook(ctx context.Context, watchRepo repository.WatchRepository, sender *webhook.Sender, delivery *models.WebhookDelivery, secret string) {
+	result := sender.Send(ctx, delivery.URL, secret, delivery.ID, webhookEvent, []byte(delivery.Payload))
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = result.StatusCode
+	delivery.LastError = ""
+	attempt := models.WebhookAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts,
+		StatusCode:  result.StatusCode,
+		DurationMs:  result.Duration.Milliseconds(),
+		AttemptedAt: now,
+	}
+
+	switch {
+	case result.Err == nil:
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		log.Printf("[WEBHOOK] delivered %s to %s", delivery.ID, delivery.URL)
+	case delivery.Attempts >= maxDeliveryAttempts:
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = result.Err.Error()
+		attempt.Error = delivery.LastError
+		log.Printf("[WEBHOOK] giving up on %s after %d attempts: %v", delivery.ID, delivery.Attempts, result.Err)
+	default:
+		delivery.LastError = result.Err.Error()
+		delivery.NextAttemptAt = now.Add(deliveryBackoff(delivery.Attempts))
+		attempt.Error = delivery.LastError
+		log.Printf("[WEBHOOK] attempt %d of %s failed, retry at %s: %v", delivery.Attempts, delivery.ID, delivery.NextAttemptAt.Format(time.RFC3339), result.Err)
+	}
+
+	if err := watchRepo.RecordAttempt(delivery, &attempt); err != nil {
+		log.Printf("[WEBHOOK_ERROR] failed to record attempt of %s: %v", delivery.ID, err)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/MrBista/The-Crawler/internal/repository"
+	"github.com/MrBista/The-Crawler/internal/webhook"
+)
+
+// attemptRecorder keeps what deliverWebhook persists. Other methods of the
+// interface are not used by deliverWebhook and panic when called.
+type attemptRecorder struct {
+	repository.WatchRepository
+	deliveries []models.WebhookDelivery
+	attempts   []models.WebhookAttempt
+}
+
+func (r *attemptRecorder) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	r.deliveries = append(r.deliveries, *delivery)
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		10: 4*time.Hour + 16*time.Minute,
+		11: 6 * time.Hour,
+		50: 6 * time.Hour,
+	}
+	for attempt, want := range tests {
+		if got := deliveryBackoff(attempt); got != want {
+			t.Errorf("deliveryBackoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestDeliverWebhookRetriesUntilDelivered(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := &attemptRecorder{}
+	sender := &webhook.Sender{Client: server.Client()}
+	delivery := &models.WebhookDelivery{ID: "delivery-1", URL: server.URL, Payload: `{}`, Status: models.DeliveryPending}
+
+	for i := 1; i <= 2; i++ {
+		before := time.Now()
+		deliverWebhook(context.Background(), repo, sender, delivery, "secret")
+
+		if delivery.Status != models.DeliveryPending || delivery.Attempts != i {
+			t.Fatalf("attempt %d: status %s, attempts %d", i, delivery.Status, delivery.Attempts)
+		}
+		if delivery.LastStatusCode != http.StatusServiceUnavailable || delivery.LastError == "" {
+			t.Errorf("attempt %d: last status %d, last error %q", i, delivery.LastStatusCode, delivery.LastError)
+		}
+		wait := delivery.NextAttemptAt.Sub(before)
+		if want := deliveryBackoff(i); wait < want || wait > want+time.Second {
+			t.Errorf("attempt %d: next attempt in %s, want %s", i, wait, want)
+		}
+	}
+
+	deliverWebhook(context.Background(), repo, sender, delivery, "secret")
+	if delivery.Status != models.DeliveryDelivered || delivery.DeliveredAt == nil {
+		t.Fatalf("status %s, delivered at %v", delivery.Status, delivery.DeliveredAt)
+	}
+	if delivery.LastError != "" || delivery.LastStatusCode != http.StatusOK {
+		t.Errorf("last status %d, last error %q", delivery.LastStatusCode, delivery.LastError)
+	}
+
+	if len(repo.attempts) != 3 {
+		t.Fatalf("recorded %d attempts, want 3", len(repo.attempts))
+	}
+	for i, attempt := range repo.attempts {
+		if attempt.DeliveryID != "delivery-1" || attempt.Attempt != i+1 {
+			t.Errorf("attempt %d recorded as %+v", i+1, attempt)
+		}
+		if failed := i < 2; failed != (attempt.Error != "") {
+			t.Errorf("attempt %d error %q", i+1, attempt.Error)
+		}
+	}
+	if repo.deliveries[2].Status != models.DeliveryDelivered {
+		t.Errorf("persisted status %s, want %s", repo.deliveries[2].Status, models.DeliveryDelivered)
+	}
+}
+
+func TestDeliverWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := &attemptRecorder{}
+	sender := &webhook.Sender{Client: server.Client()}
+	delivery := &models.WebhookDelivery{ID: "delivery-1", URL: server.URL, Payload: `{}`, Status: models.DeliveryPending}
+
+	for i := 1; i < maxDeliveryAttempts; i++ {
+		deliverWebhook(context.Background(), repo, sender, delivery, "secret")
+		if delivery.Status != models.DeliveryPending {
+			t.Fatalf("attempt %d: status %s, want %s", i, delivery.Status, models.DeliveryPending)
+		}
+	}
+
+	nextAttempt := delivery.NextAttemptAt
+	deliverWebhook(context.Background(), repo, sender, delivery, "secret")
+
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != maxDeliveryAttempts {
+		t.Fatalf("status %s after %d attempts, want %s after %d", delivery.Status, delivery.Attempts, models.DeliveryFailed, maxDeliveryAttempts)
+	}
+	if delivery.LastError == "" || delivery.DeliveredAt != nil {
+		t.Errorf("last error %q, delivered at %v", delivery.LastError, delivery.DeliveredAt)
+	}
+	// Delivery yang gagal tidak dijadwalkan ulang
+	if !delivery.NextAttemptAt.Equal(nextAttempt) {
+		t.Errorf("next attempt moved to %s", delivery.NextAttemptAt)
+	}
+	if len(repo.attempts) != maxDeliveryAttempts {
+		t.Errorf("recorded %d attempts, want %d", len(repo.attempts), maxDeliveryAttempts)
+	}
+}
@@ -3,39 +3,193 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
 type CrawlJob struct {
 	ID        string   `json:"id"`
 	ParentId  string   `json:"parent_id"`
+	SessionId string   `json:"session_id"`
 	Url       string   `json:"url"`
 	Depth     int      `json:"depth"`
 	Selectors []string `json:"selectors"`
+	// Schema menggantikan Selectors kalau diisi
+	Schema  []ExtractField `json:"schema,omitempty"`
+	RootUrl string         `json:"root_url"`
+	Scope   CrawlScope     `json:"scope"`
+	// Budget hanya dibaca saat submit dan disimpan di session
+	Budget *CrawlBudget `json:"budget,omitempty"`
+	// Attempt dihitung dari 0, NotBefore diisi saat job masuk topic retry
+	Attempt   int        `json:"attempt,omitempty"`
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	// Mode kosong sama dengan crawl
+	Mode string `json:"mode,omitempty"`
+	// CheckOnly hanya memeriksa status URL tanpa mengunduh isinya (mode link_check)
+	CheckOnly bool `json:"check_only,omitempty"`
+	// RedirectPolicy kosong sama dengan follow
+	RedirectPolicy string `json:"redirect_policy,omitempty"`
+	// BinaryPolicy kosong sama dengan skip
+	BinaryPolicy string `json:"binary_policy,omitempty"`
+	// SitemapSince melewati entry sitemap dengan lastmod sebelum waktu ini (mode sitemap)
+	SitemapSince *time.Time `json:"sitemap_since,omitempty"`
+	// Sitemap diisi pada job yang berasal dari entry sitemap
+	Sitemap *SitemapSource `json:"sitemap,omitempty"`
+	// FeedItem diisi pada job yang berasal dari item feed
+	FeedItem *FeedItem `json:"feed_item,omitempty"`
+	// WatchID diisi pada root job yang dijadwalkan oleh watch
+	WatchID string `json:"watch_id,omitempty"`
+}
+
+// FeedItem is the feed entry a job was created from.
+type FeedItem struct {
+	FeedURL   string     `json:"feed_url"`
+	GUID      string     `json:"guid"`
+	Title     string     `json:"title"`
+	Author    string     `json:"author"`
+	Published *time.Time `json:"published,omitempty"`
+}
+
+// SitemapSource is the sitemap entry a job was seeded from.
+type SitemapSource struct {
+	URL        string     `json:"url"`
+	Lastmod    *time.Time `json:"lastmod,omitempty"`
+	Priority   *float64   `json:"priority,omitempty"`
+	ChangeFreq string     `json:"changefreq,omitempty"`
 }
 
 type CrawlPage struct {
-	ID         string  `gorm:"primaryKey;type:uuid"`
-	ParentID   *string `gorm:"type:uuid;index"` // Pointer agar bisa null (root)
-	URL        string  `gorm:"not null"`
-	Title      string  `gorm:"type:text"`
-	FilePath   string  `gorm:"type:text"`  // Lokasi file .html
-	ParsedData JSONB   `gorm:"type:jsonb"` // Hasil ekstraksi selector
-	Status     string  `gorm:"type:varchar(20)"`
-	DepthLevel int     `gorm:"type:int"`
-	CreatedAt  time.Time
+	ID           string  `gorm:"primaryKey;type:uuid" json:"id"`
+	ParentID     *string `gorm:"type:uuid;index" json:"parent_id"` // Pointer agar bisa null (root)
+	SessionID    string  `gorm:"type:uuid;index" json:"session_id"`
+	URL          string  `gorm:"not null;index" json:"url"`
+	CanonicalURL string  `gorm:"type:text;index" json:"canonical_url"`
+	Title        string  `gorm:"type:text" json:"title"`
+	// FinalURL adalah URL setelah redirect, isi halaman berasal dari sini
+	FinalURL      string        `gorm:"type:text;index" json:"final_url"`
+	RedirectChain RedirectChain `gorm:"type:jsonb" json:"redirect_chain"`
+	// Sitemap tempat URL ini ditemukan, kosong kalau berasal dari link biasa
+	SitemapURL      string     `gorm:"type:text" json:"sitemap_url"`
+	SitemapLastmod  *time.Time `json:"sitemap_lastmod"`
+	SitemapPriority *float64   `json:"sitemap_priority"`
+	// Metadata item feed, kosong kalau halaman tidak berasal dari feed
+	FeedURL         string     `gorm:"type:text" json:"feed_url"`
+	FeedGUID        string     `gorm:"type:text" json:"feed_guid"`
+	FeedItemTitle   string     `gorm:"type:text" json:"feed_item_title"`
+	FeedAuthor      string     `gorm:"type:text" json:"feed_author"`
+	FeedPublishedAt *time.Time `json:"feed_published_at"`
+	// ContentType tanpa parameter charset, ContentLength ukuran body yang diunduh
+	ContentType   string `gorm:"type:varchar(255)" json:"content_type"`
+	ContentLength int64  `json:"content_length"`
+	// Charset encoding asli halaman, isi yang diparse dan disimpan di database sudah UTF-8
+	Charset string `gorm:"type:varchar(40)" json:"charset"`
+	// Dimensi gambar dan jumlah halaman PDF, nol untuk jenis lain
+	ImageWidth  int    `gorm:"type:int" json:"image_width"`
+	ImageHeight int    `gorm:"type:int" json:"image_height"`
+	PageCount   int    `gorm:"type:int" json:"page_count"`
+	FilePath    string `gorm:"type:text" json:"file_path"` // Lokasi file asli (.html, .pdf, .json, ...)
+	// Validator dari respons terakhir, dikirim lagi saat URL yang sama di-crawl ulang
+	ETag         string `gorm:"type:text" json:"etag"`
+	LastModified string `gorm:"type:text" json:"last_modified"`
+	// ContentHash adalah sha256 body asli, dipakai kalau server tidak mendukung validator
+	ContentHash string `gorm:"type:varchar(64)" json:"content_hash"`
+	// ExtractHash sidik opsi ekstraksi job, crawl ulang hanya memakai halaman dengan opsi yang sama
+	ExtractHash string `gorm:"type:varchar(64);index" json:"extract_hash"`
+	ParsedData  JSONB  `gorm:"type:jsonb" json:"parsed_data"` // Hasil ekstraksi selector
+	// JSON-LD, Microdata, RDFa, OpenGraph dan Twitter Card yang dipublikasikan halaman
+	StructuredData JSONB `gorm:"type:jsonb" json:"structured_data"`
+	// Hasil readability, isi lengkapnya disimpan sebagai file .md dan .txt
+	Byline       string     `gorm:"type:text" json:"byline"`
+	PublishedAt  *time.Time `json:"published_at"`
+	LeadImage    string     `gorm:"type:text" json:"lead_image"`
+	Excerpt      string     `gorm:"type:text" json:"excerpt"`
+	WordCount    int        `gorm:"type:int" json:"word_count"`
+	MarkdownPath string     `gorm:"type:text" json:"markdown_path"`
+	TextPath     string     `gorm:"type:text" json:"text_path"`
+	// Identitas halaman berdasarkan canonical URL dan versi isinya saat crawl ini
+	PageIdentityID *string   `gorm:"type:uuid;index" json:"page_identity_id"`
+	PageVersion    int       `gorm:"type:int" json:"page_version"`
+	Status         string    `gorm:"type:varchar(20)" json:"status"`
+	DepthLevel     int       `gorm:"type:int" json:"depth_level"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 func (c *CrawlJob) TableName() string {
 	return "crawl_page"
 }
 
-type JSONB map[string]string
+// JSONB holds arbitrary JSON objects, values keep the types produced by the
+// extraction schema.
+type JSONB map[string]interface{}
 
 func (j JSONB) Value() (driver.Value, error) {
 	return json.Marshal(j)
 }
 
 func (j *JSONB) Scan(value interface{}) error {
-	return json.Unmarshal(value.([]byte), j)
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, j)
+	case string:
+		return json.Unmarshal([]byte(v), j)
+	default:
+		return fmt.Errorf("unsupported jsonb type %T", value)
+	}
+}
+
+// StringList is a JSON array column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("unsupported jsonb type %T", value)
+	}
+}
+
+// RedirectChain lists every redirect response on the way to a page, in order.
+type RedirectChain []RedirectHop
+
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
+
+func (c RedirectChain) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal([]RedirectHop{})
+	}
+	return json.Marshal(c)
+}
+
+func (c *RedirectChain) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("unsupported jsonb type %T", value)
+	}
 }
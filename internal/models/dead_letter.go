@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DeadLetter is a job that exhausted its retries, failed permanently, or a
+// message that could not be decoded at all.
+type DeadLetter struct {
+	ID          string     `gorm:"primaryKey;type:uuid" json:"id"`
+	JobID       *string    `gorm:"type:uuid;index" json:"job_id"` // Kosong untuk pesan yang tidak bisa di-parse
+	SessionID   *string    `gorm:"type:uuid;index" json:"session_id"`
+	SourceTopic string     `gorm:"type:varchar(255)" json:"source_topic"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	Reason      string     `gorm:"type:text" json:"reason"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReplayedAt  *time.Time `json:"replayed_at"`
+}
+
+func (DeadLetter) TableName() string {
+	return "dead_letters"
+}
@@ -0,0 +1,8 @@
+package models
+
+const (
+	// BinaryPolicySkip melewati respons yang bukan HTML, PDF, JSON, gambar atau teks
+	BinaryPolicySkip = "skip"
+	// BinaryPolicyStore menyimpan file apa adanya tanpa ekstraksi
+	BinaryPolicyStore = "store"
+)
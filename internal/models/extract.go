@@ -0,0 +1,37 @@
+package models
+
+const (
+	ExtractText      = "text"
+	ExtractHTML      = "html"
+	ExtractOuterHTML = "outer_html"
+	ExtractAttr      = "attr"
+)
+
+const (
+	FieldTypeString = "string"
+	FieldTypeInt    = "int"
+	FieldTypeFloat  = "float"
+	FieldTypeBool   = "bool"
+	FieldTypeDate   = "date"
+	FieldTypeURL    = "url"
+)
+
+// ExtractField describes one named value pulled out of a crawled page. A field
+// with Fields is an object built from each matched element, its children are
+// evaluated relative to that element. Selector applies to HTML pages and Path
+// to JSON documents, a field may declare both.
+type ExtractField struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+	// Path memakai notasi titik, mis. "data.items[*].title" atau "$.meta[0]"
+	Path string `json:"path"`
+	// Extract kosong sama dengan text, attr wajib mengisi Attr (mis. href, src)
+	Extract string `json:"extract"`
+	Attr    string `json:"attr"`
+	// List mengembalikan semua elemen yang cocok, selain itu hanya elemen pertama
+	List bool   `json:"list"`
+	Type string `json:"type"`
+	// DateFormat memakai layout Go, kosong berarti dicoba beberapa format umum
+	DateFormat string         `json:"date_format"`
+	Fields     []ExtractField `json:"fields"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// HostThrottle holds the earliest time the next request to a host may start,
+// shared by every worker process.
+type HostThrottle struct {
+	Host          string    `gorm:"primaryKey;type:varchar(255)"`
+	NextRequestAt time.Time `gorm:"not null"`
+}
+
+func (HostThrottle) TableName() string {
+	return "host_throttles"
+}
+
+// HostLease is one in-flight request to a host. Leases expire so a crashed
+// worker can't hold a connection slot forever.
+type HostLease struct {
+	ID        string    `gorm:"primaryKey;type:uuid"`
+	Host      string    `gorm:"type:varchar(255);not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (HostLease) TableName() string {
+	return "host_leases"
+}
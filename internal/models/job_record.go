@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+const (
+	JobStatusQueued        = "queued"
+	JobStatusFetching      = "fetching"
+	JobStatusSucceeded     = "succeeded"
+	JobStatusFailed        = "failed"
+	JobStatusSkipped       = "skipped"
+	JobStatusSkippedRobots = "skipped_robots"
+	JobStatusCancelled     = "cancelled"
+	JobStatusPaused        = "paused"
+)
+
+// jobTransitions maps a target status to the statuses a job may move from.
+// Fetching is re-entrant so a message redelivered after a worker crash can
+// be picked up again. Queued is re-entered by retries, dead-letter replay and
+// resuming a paused session.
+var jobTransitions = map[string][]string{
+	JobStatusQueued:        {JobStatusFetching, JobStatusFailed, JobStatusPaused},
+	JobStatusFetching:      {JobStatusQueued, JobStatusFetching},
+	JobStatusSucceeded:     {JobStatusFetching},
+	JobStatusFailed:        {JobStatusQueued, JobStatusFetching},
+	JobStatusSkipped:       {JobStatusQueued, JobStatusFetching},
+	JobStatusSkippedRobots: {JobStatusQueued, JobStatusFetching},
+	JobStatusCancelled:     {JobStatusQueued, JobStatusFetching, JobStatusPaused},
+	JobStatusPaused:        {JobStatusQueued, JobStatusFetching},
+}
+
+// jobStatusTimestamps holds the column stamped when a job enters a status.
+var jobStatusTimestamps = map[string]string{
+	JobStatusQueued:        "queued_at",
+	JobStatusFetching:      "fetching_at",
+	JobStatusSucceeded:     "succeeded_at",
+	JobStatusFailed:        "failed_at",
+	JobStatusSkipped:       "skipped_at",
+	JobStatusSkippedRobots: "skipped_at",
+	JobStatusCancelled:     "cancelled_at",
+	JobStatusPaused:        "paused_at",
+}
+
+// CrawlJobRecord is the persisted lifecycle of a CrawlJob message.
+type CrawlJobRecord struct {
+	ID              string     `gorm:"primaryKey;type:uuid" json:"id"`
+	ParentID        *string    `gorm:"type:uuid;index" json:"parent_id"`
+	SessionID       string     `gorm:"type:uuid;index" json:"session_id"`
+	URL             string     `gorm:"not null" json:"url"`
+	Depth           int        `gorm:"type:int" json:"depth"`
+	Status          string     `gorm:"type:varchar(20);index" json:"status"`
+	FailureReason   string     `gorm:"type:text" json:"failure_reason"`
+	HTTPStatus      int        `gorm:"type:int" json:"http_status"`
+	BytesDownloaded int64      `gorm:"not null;default:0" json:"bytes_downloaded"`
+	Attempts        int        `gorm:"not null;default:0" json:"attempts"`
+	QueuedAt        *time.Time `json:"queued_at"`
+	FetchingAt      *time.Time `json:"fetching_at"`
+	SucceededAt     *time.Time `json:"succeeded_at"`
+	FailedAt        *time.Time `json:"failed_at"`
+	SkippedAt       *time.Time `json:"skipped_at"`
+	CancelledAt     *time.Time `json:"cancelled_at"`
+	PausedAt        *time.Time `json:"paused_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (CrawlJobRecord) TableName() string {
+	return "crawl_jobs"
+}
+
+// JobTransitionSources returns the statuses a job may be in to move to status.
+func JobTransitionSources(status string) []string {
+	return jobTransitions[status]
+}
+
+func JobStatusTimestampColumn(status string) string {
+	return jobStatusTimestamps[status]
+}
+
+func IsTerminalJobStatus(status string) bool {
+	switch status {
+	case JobStatusSucceeded, JobStatusFailed, JobStatusSkipped, JobStatusSkippedRobots, JobStatusCancelled:
+		return true
+	}
+	return false
+}
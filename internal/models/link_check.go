@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	CrawlModeCrawl = "crawl"
+	// CrawlModeLinkCheck crawls in-scope pages as usual and only probes every
+	// link, image, script and stylesheet they reference
+	CrawlModeLinkCheck = "link_check"
+	// CrawlModeSitemap seeds the session from the sitemaps of the root host
+	// before crawling the root page
+	CrawlModeSitemap = "sitemap"
+	// CrawlModeFeed reads the root URL as an RSS, Atom or JSON Feed and
+	// enqueues the link of every item not seen in earlier polls
+	CrawlModeFeed = "feed"
+)
+
+// LinkCheck is the probe result of one URL within a link_check session.
+type LinkCheck struct {
+	SessionID  string `gorm:"primaryKey;type:uuid" json:"session_id"`
+	URL        string `gorm:"primaryKey;type:text" json:"url"`
+	JobID      string `gorm:"type:uuid" json:"job_id"`
+	Method     string `gorm:"type:varchar(10)" json:"method"` // HEAD, atau GET kalau server menolak HEAD
+	StatusCode int    `gorm:"type:int" json:"status_code"`
+	FinalURL   string `gorm:"type:text" json:"final_url"`
+	// RedirectChain berisi URL yang dilewati sebelum FinalURL
+	RedirectChain StringList `gorm:"type:jsonb" json:"redirect_chain"`
+	Error         string     `gorm:"type:text" json:"error"`
+	TimedOut      bool       `gorm:"not null;default:false" json:"timed_out"`
+	Broken        bool       `gorm:"not null;default:false;index" json:"broken"`
+	DurationMs    int64      `json:"duration_ms"`
+	CheckedAt     time.Time  `json:"checked_at"`
+}
+
+func (LinkCheck) TableName() string {
+	return "link_checks"
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	LinkKindLink       = "link"
+	LinkKindImage      = "image"
+	LinkKindScript     = "script"
+	LinkKindStylesheet = "stylesheet"
+)
+
+// PageLink is one <a href> found on a crawled page. Every link is recorded,
+// including those outside the crawl scope or already visited, so the table
+// holds the full link graph of a session. Link check sessions also record
+// images, scripts and stylesheets, told apart by Kind.
+type PageLink struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	SessionID    string `gorm:"type:uuid;index:idx_page_links_session_target" json:"session_id"`
+	SourcePageID string `gorm:"type:uuid;not null;index" json:"source_page_id"`
+	SourceURL    string `gorm:"type:text" json:"source_url"`
+	// TargetURL sudah dikanonikalisasi supaya cocok dengan crawl_pages.canonical_url
+	TargetURL  string `gorm:"type:text;not null;index:idx_page_links_session_target" json:"target_url"`
+	AnchorText string `gorm:"type:text" json:"anchor_text"`
+	Kind       string `gorm:"type:varchar(20);not null;default:'link'" json:"kind"`
+	// Rel disimpan apa adanya dipisah spasi, mis. "nofollow sponsored"
+	Rel       string    `gorm:"type:varchar(255)" json:"rel"`
+	Nofollow  bool      `gorm:"not null;default:false" json:"nofollow"`
+	Position  int       `gorm:"not null" json:"position"` // Urutan link di DOM, mulai dari 0
+	Internal  bool      `gorm:"not null;default:false" json:"internal"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PageLink) TableName() string {
+	return "page_links"
+}
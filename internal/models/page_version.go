@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PageIdentity is one canonical URL crawled with one set of extraction
+// options, followed across crawls and sessions. Every crawl of it that
+// produces different content adds a PageVersion.
+type PageIdentity struct {
+	ID           string `gorm:"primaryKey;type:uuid" json:"id"`
+	CanonicalURL string `gorm:"type:text;not null;uniqueIndex:idx_page_identities_url_extract" json:"canonical_url"`
+	// ExtractHash sama dengan CrawlPage.ExtractHash, ParsedData dari selector
+	// atau schema lain tidak dibandingkan dengan riwayat ini
+	ExtractHash   string `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_page_identities_url_extract" json:"extract_hash"`
+	LatestVersion int    `gorm:"not null;default:0" json:"latest_version"`
+	// LastCrawlPageID adalah crawl terakhir URL ini, berubah atau tidak
+	LastCrawlPageID string    `gorm:"type:uuid" json:"last_crawl_page_id"`
+	FirstSeenAt     time.Time `json:"first_seen_at"`
+	LastCrawledAt   time.Time `json:"last_crawled_at"`
+	LastChangedAt   time.Time `json:"last_changed_at"`
+}
+
+func (PageIdentity) TableName() string {
+	return "page_identities"
+}
+
+// PageVersion keeps the text and extracted fields of one distinct content of
+// a page, with the diff against the version before it.
+type PageVersion struct {
+	ID             string `gorm:"primaryKey;type:uuid" json:"id"`
+	PageIdentityID string `gorm:"type:uuid;not null;uniqueIndex:idx_page_versions_page_version" json:"page_identity_id"`
+	Version        int    `gorm:"not null;uniqueIndex:idx_page_versions_page_version" json:"version"`
+	CrawlPageID    string `gorm:"type:uuid;not null;index" json:"crawl_page_id"`
+	SessionID      string `gorm:"type:uuid" json:"session_id"`
+	// ContentHash dihitung dari teks dan ParsedData, bukan byte HTML, jadi
+	// perubahan markup saja tidak membuat versi baru
+	ContentHash string `gorm:"type:varchar(64);not null" json:"content_hash"`
+	Title       string `gorm:"type:text" json:"title"`
+	Text        string `gorm:"type:text" json:"text,omitempty"`
+	ParsedData  JSONB  `gorm:"type:jsonb" json:"parsed_data"`
+	// Diff terhadap versi sebelumnya, kosong untuk versi pertama
+	TextDiff     string          `gorm:"type:text" json:"text_diff,omitempty"`
+	LinesAdded   int             `gorm:"not null;default:0" json:"lines_added"`
+	LinesRemoved int             `gorm:"not null;default:0" json:"lines_removed"`
+	FieldChanges FieldChangeList `gorm:"type:jsonb" json:"field_changes"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func (PageVersion) TableName() string {
+	return "page_versions"
+}
+
+const (
+	FieldAdded   = "added"
+	FieldRemoved = "removed"
+	FieldChanged = "changed"
+)
+
+// FieldChange is one difference between the ParsedData of two versions.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Change string      `json:"change"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+}
+
+type FieldChangeList []FieldChange
+
+func (l FieldChangeList) Value() (driver.Value, error) {
+	if l == nil {
+		return json.Marshal([]FieldChange{})
+	}
+	return json.Marshal(l)
+}
+
+func (l *FieldChangeList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("unsupported jsonb type %T", value)
+	}
+}
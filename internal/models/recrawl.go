@@ -0,0 +1,6 @@
+package models
+
+// PageStatusUnchanged marks a recrawled page whose content matched the last
+// crawl of the same URL, either by a 304 response or by content hash. Its file
+// paths and extracted data point at the earlier crawl.
+const PageStatusUnchanged = "unchanged"
@@ -0,0 +1,13 @@
+package models
+
+const (
+	RedirectFollow = "follow"
+	// RedirectSameHost hanya mengikuti redirect ke host yang sama dengan URL job
+	RedirectSameHost = "same_host"
+	// RedirectNone tidak mengikuti redirect, hop dan tujuannya tetap dicatat
+	RedirectNone = "none"
+)
+
+// PageStatusRedirected marks a page whose redirect was recorded but not
+// followed, it has no content.
+const PageStatusRedirected = "redirected"
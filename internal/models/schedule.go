@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// OverlapSkip tidak memulai run baru selama session run sebelumnya masih berjalan
+	OverlapSkip  = "skip"
+	OverlapAllow = "allow"
+)
+
+const (
+	ScheduleRunStarted = "started"
+	// ScheduleRunSkipped berarti run dilewati karena overlap policy
+	ScheduleRunSkipped = "skipped"
+	ScheduleRunFailed  = "failed"
+)
+
+// CrawlSchedule starts a crawl from Template on a cron expression or a fixed
+// interval. Exactly one of Cron and IntervalSeconds is set.
+type CrawlSchedule struct {
+	ID   string `gorm:"primaryKey;type:uuid" json:"id"`
+	Name string `gorm:"type:varchar(255)" json:"name"`
+	// Template memakai body yang sama dengan POST /api/v1/crawl
+	Template        CrawlTemplate `gorm:"type:jsonb;not null" json:"template"`
+	Cron            string        `gorm:"type:varchar(120)" json:"cron"`
+	IntervalSeconds int           `json:"interval_seconds"`
+	// TimeZone nama IANA, mis. "Asia/Jakarta", kosong sama dengan UTC
+	TimeZone string `gorm:"type:varchar(64)" json:"time_zone"`
+	// JitterSeconds menggeser setiap run secara acak agar schedule tidak serentak
+	JitterSeconds int    `json:"jitter_seconds"`
+	OverlapPolicy string `gorm:"type:varchar(10);not null" json:"overlap_policy"`
+	Active        bool   `gorm:"not null;default:true" json:"active"`
+	// NextRunAt sudah termasuk jitter
+	NextRunAt     time.Time  `gorm:"index" json:"next_run_at"`
+	LastRunAt     *time.Time `json:"last_run_at"`
+	LastSessionID string     `gorm:"type:varchar(36)" json:"last_session_id"`
+	LastStatus    string     `gorm:"type:varchar(20)" json:"last_status"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (CrawlSchedule) TableName() string {
+	return "crawl_schedules"
+}
+
+// CrawlTemplate stores the options of a crawl request as a jsonb column.
+type CrawlTemplate CrawlJob
+
+func (t CrawlTemplate) Value() (driver.Value, error) {
+	return json.Marshal(CrawlJob(t))
+}
+
+func (t *CrawlTemplate) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = CrawlTemplate{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*CrawlJob)(t))
+	case string:
+		return json.Unmarshal([]byte(v), (*CrawlJob)(t))
+	default:
+		return fmt.Errorf("unsupported jsonb type %T", value)
+	}
+}
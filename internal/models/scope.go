@@ -0,0 +1,45 @@
+package models
+
+const (
+	ScopeAny        = "any"
+	ScopeSameHost   = "same_host"
+	ScopeSameDomain = "same_domain"
+	ScopeSamePath   = "same_path"
+	ScopeAllowlist  = "allowlist"
+)
+
+const (
+	PatternGlob  = "glob"
+	PatternRegex = "regex"
+)
+
+const (
+	QueryKeep  = "keep"
+	QueryStrip = "strip"
+	QuerySkip  = "skip"
+)
+
+// CrawlScope limits which discovered links a recursive crawl follows. It is
+// copied unchanged to every child job, the root URL it is relative to travels
+// in CrawlJob.RootUrl.
+type CrawlScope struct {
+	// Mode kosong sama dengan any supaya job lama tetap berjalan seperti sebelumnya
+	Mode string `json:"mode"`
+	// AllowedDomains dipakai mode allowlist, subdomain ikut diizinkan
+	AllowedDomains []string `json:"allowed_domains"`
+	// PathPrefix dipakai mode same_path, default direktori dari root URL
+	PathPrefix string `json:"path_prefix"`
+
+	// Include dan Exclude memakai PatternSyntax. Glob dicocokkan dengan path
+	// (plus query), "*" tidak melewati "/" dan "**" cocok dengan apa saja.
+	// Regex dicari di URL lengkap.
+	Include       []string `json:"include"`
+	Exclude       []string `json:"exclude"`
+	PatternSyntax string   `json:"pattern_syntax"`
+
+	// URL tanpa ekstensi selalu lolos filter ekstensi
+	AllowedExtensions  []string `json:"allowed_extensions"`
+	ExcludedExtensions []string `json:"excluded_extensions"`
+
+	QueryPolicy string `json:"query_policy"`
+}
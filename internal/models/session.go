@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+const (
+	SessionStatusRunning         = "running"
+	SessionStatusCompleted       = "completed"
+	SessionStatusBudgetExhausted = "budget_exhausted"
+	SessionStatusPaused          = "paused"
+	SessionStatusCancelled       = "cancelled"
+)
+
+// sessionTransitions maps a target status to the statuses a session may be
+// moved from by the pause, resume and cancel endpoints.
+var sessionTransitions = map[string][]string{
+	SessionStatusPaused:    {SessionStatusRunning},
+	SessionStatusRunning:   {SessionStatusPaused},
+	SessionStatusCancelled: {SessionStatusRunning, SessionStatusPaused, SessionStatusBudgetExhausted},
+}
+
+func SessionTransitionSources(status string) []string {
+	return sessionTransitions[status]
+}
+
+// CrawlBudget bounds a whole crawl session. Zero values mean unlimited.
+type CrawlBudget struct {
+	MaxPages           int64      `json:"max_pages"`
+	MaxBytes           int64      `json:"max_bytes"`
+	MaxPagesPerHost    int64      `json:"max_pages_per_host"`
+	Deadline           *time.Time `json:"deadline"`
+	MaxDurationSeconds int64      `json:"max_duration_seconds"` // Alternatif Deadline relatif dari waktu submit
+}
+
+// CrawlSession aggregates every job produced by one submitted crawl. Its ID is
+// the root job ID and is carried by every descendant job and page.
+type CrawlSession struct {
+	ID              string     `gorm:"primaryKey;type:uuid" json:"id"`
+	RootURL         string     `gorm:"not null" json:"root_url"`
+	Status          string     `gorm:"type:varchar(20);index" json:"status"`
+	PagesQueued     int64      `gorm:"not null;default:0" json:"pages_queued"`
+	ChecksQueued    int64      `gorm:"not null;default:0" json:"checks_queued"` // Probe link_check di dalam PagesQueued, tidak dihitung ke max_pages
+	PagesFetched    int64      `gorm:"not null;default:0" json:"pages_fetched"`
+	PagesFailed     int64      `gorm:"not null;default:0" json:"pages_failed"`
+	PagesSkipped    int64      `gorm:"not null;default:0" json:"pages_skipped"`
+	BytesDownloaded int64      `gorm:"not null;default:0" json:"bytes_downloaded"`
+	MaxPages        int64      `gorm:"not null;default:0" json:"max_pages"`
+	MaxBytes        int64      `gorm:"not null;default:0" json:"max_bytes"`
+	MaxPagesPerHost int64      `gorm:"not null;default:0" json:"max_pages_per_host"`
+	Deadline        *time.Time `json:"deadline"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+}
+
+func (CrawlSession) TableName() string {
+	return "crawl_sessions"
+}
+
+// PagesPending is the number of queued jobs that have not reached a terminal status yet.
+func (s *CrawlSession) PagesPending() int64 {
+	pending := s.PagesQueued - s.PagesFetched - s.PagesFailed - s.PagesSkipped
+	if pending < 0 {
+		return 0
+	}
+	return pending
+}
+
+// SessionHostCount counts pages queued per host for MaxPagesPerHost.
+type SessionHostCount struct {
+	SessionID string `gorm:"primaryKey;type:uuid"`
+	Host      string `gorm:"primaryKey;type:varchar(255)"`
+	Pages     int64  `gorm:"not null;default:0"`
+}
+
+func (SessionHostCount) TableName() string {
+	return "session_host_counts"
+}
+
+// ParkedJob holds a job consumed while its session was paused so resume can
+// publish it again instead of dropping it.
+type ParkedJob struct {
+	JobID     string `gorm:"primaryKey;type:uuid"`
+	SessionID string `gorm:"type:uuid;not null;index"`
+	Payload   string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+func (ParkedJob) TableName() string {
+	return "parked_jobs"
+}
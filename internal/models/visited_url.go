@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// VisitedURL marks a URL as already enqueued within a crawl session.
+type VisitedURL struct {
+	ID        uint   `gorm:"primaryKey"`
+	SessionID string `gorm:"type:varchar(255);not null;uniqueIndex:idx_visited_session_url"`
+	URL       string `gorm:"type:text;not null;uniqueIndex:idx_visited_session_url"`
+	CreatedAt time.Time
+}
+
+func (VisitedURL) TableName() string {
+	return "visited_urls"
+}
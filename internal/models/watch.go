@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// WatchAnyChange fires whenever the page gets a new version
+	WatchAnyChange = "any_change"
+	// WatchFieldChanged fires when one field of the extraction schema changes
+	WatchFieldChanged = "field_changed"
+	// WatchThreshold fires when a numeric field crosses Threshold in Direction
+	WatchThreshold = "threshold"
+	// WatchTextAppeared and WatchTextDisappeared look for Text in the page
+	// text, or in Field when it is set
+	WatchTextAppeared    = "text_appeared"
+	WatchTextDisappeared = "text_disappeared"
+)
+
+const (
+	ThresholdAbove = "above"
+	ThresholdBelow = "below"
+	// ThresholdCross fires in both directions
+	ThresholdCross = "cross"
+)
+
+// Watch crawls one URL on an interval and notifies a webhook when its
+// condition holds between the version it saw last and the new one.
+type Watch struct {
+	ID   string `gorm:"primaryKey;type:uuid" json:"id"`
+	Name string `gorm:"type:varchar(255)" json:"name"`
+	URL  string `gorm:"type:text;not null" json:"url"`
+	// Schema dipakai untuk ekstraksi seperti pada POST /api/v1/crawl
+	Schema          ExtractSchema `gorm:"type:jsonb" json:"schema"`
+	IntervalSeconds int           `gorm:"not null" json:"interval_seconds"`
+	Condition       string        `gorm:"type:varchar(30);not null" json:"condition"`
+	// Field memakai path bertitik seperti di field_changes, mis. "price" atau "offer.price"
+	Field      string   `gorm:"type:text" json:"field"`
+	Threshold  *float64 `json:"threshold"`
+	Direction  string   `gorm:"type:varchar(10)" json:"direction"`
+	Text       string   `gorm:"type:text" json:"text"`
+	WebhookURL string   `gorm:"type:text;not null" json:"webhook_url"`
+	// Secret untuk HMAC signature webhook, hanya dikembalikan saat watch dibuat
+	Secret string `gorm:"type:varchar(128);not null" json:"-"`
+	Active bool   `gorm:"not null;default:true" json:"active"`
+	// Versi halaman terakhir yang sudah dibandingkan oleh watch ini
+	PageIdentityID *string    `gorm:"type:uuid" json:"page_identity_id"`
+	LastVersion    int        `gorm:"not null;default:0" json:"last_version"`
+	LastSessionID  string     `gorm:"type:varchar(36)" json:"last_session_id"`
+	NextRunAt      time.Time  `gorm:"index" json:"next_run_at"`
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastFiredAt    *time.Time `json:"last_fired_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Watch) TableName() string {
+	return "watches"
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	// DeliveryFailed berarti semua percobaan habis
+	DeliveryFailed = "failed"
+)
+
+// WebhookDelivery is one notification of a watch, retried with backoff until
+// the receiver answers 2xx or the attempts run out.
+type WebhookDelivery struct {
+	ID             string     `gorm:"primaryKey;type:uuid" json:"id"`
+	WatchID        string     `gorm:"type:uuid;not null;index" json:"watch_id"`
+	URL            string     `gorm:"type:text;not null" json:"url"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_deliveries_due" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookAttempt is the outcome of one HTTP request of a delivery.
+type WebhookAttempt struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeliveryID  string    `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	Attempt     int       `gorm:"not null" json:"attempt"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `gorm:"type:text" json:"error"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+func (WebhookAttempt) TableName() string {
+	return "webhook_attempts"
+}
+
+// ExtractSchema stores an extraction schema as a jsonb column.
+type ExtractSchema []ExtractField
+
+func (s ExtractSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return json.Marshal([]ExtractField{})
+	}
+	return json.Marshal(s)
+}
+
+func (s *ExtractSchema) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("unsupported jsonb type %T", value)
+	}
+}
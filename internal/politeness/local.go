@@ -0,0 +1,76 @@
+package politeness
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type hostState struct {
+	mu   sync.Mutex
+	next time.Time
+	sem  chan struct{}
+}
+
+// LocalLimiter enforces per-host limits inside one process, for single-node
+// runs where the Postgres token table is not needed.
+type LocalLimiter struct {
+	policies Policies
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+func NewLocalLimiter(policies Policies) *LocalLimiter {
+	return &LocalLimiter{
+		policies: policies,
+		hosts:    make(map[string]*hostState),
+	}
+}
+
+func (l *LocalLimiter) state(host string) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostState{
+			sem: make(chan struct{}, l.policies.For(host).maxConcurrent()),
+		}
+		l.hosts[host] = state
+	}
+	return state
+}
+
+func (l *LocalLimiter) Acquire(ctx context.Context, host string, crawlDelay time.Duration) (func(), error) {
+	host = strings.ToLower(host)
+	policy := l.policies.For(host)
+	state := l.state(host)
+
+	select {
+	case state.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { <-state.sem })
+	}
+
+	// Reservasi slot waktu dulu, baru tidur di luar lock
+	state.mu.Lock()
+	start := time.Now()
+	if state.next.After(start) {
+		start = state.next
+	}
+	state.next = start.Add(policy.Interval(crawlDelay))
+	state.mu.Unlock()
+
+	if err := sleepUntil(ctx, start); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
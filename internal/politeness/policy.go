@@ -0,0 +1,98 @@
+package politeness
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Policy bounds how hard a single host may be hit.
+type Policy struct {
+	RequestsPerSecond float64
+	MaxConcurrent     int
+	MinDelay          time.Duration
+}
+
+// Interval is the minimum spacing between two request starts to the host,
+// taking the robots.txt Crawl-delay into account.
+func (p Policy) Interval(crawlDelay time.Duration) time.Duration {
+	var interval time.Duration
+	if p.RequestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / p.RequestsPerSecond)
+	}
+	if p.MinDelay > interval {
+		interval = p.MinDelay
+	}
+	if crawlDelay > interval {
+		interval = crawlDelay
+	}
+	return interval
+}
+
+func (p Policy) maxConcurrent() int {
+	if p.MaxConcurrent < 1 {
+		return 1
+	}
+	return p.MaxConcurrent
+}
+
+// Policies resolves the policy for a host. An override for "example.com"
+// also applies to its subdomains; unset override fields inherit Default.
+type Policies struct {
+	Default   Policy
+	Overrides map[string]Policy
+}
+
+func (p Policies) For(host string) Policy {
+	host = strings.ToLower(host)
+
+	for candidate := host; candidate != ""; {
+		if override, ok := p.Overrides[candidate]; ok {
+			return p.merge(override)
+		}
+		_, rest, found := strings.Cut(candidate, ".")
+		if !found {
+			break
+		}
+		candidate = rest
+	}
+	return p.Default
+}
+
+func (p Policies) merge(override Policy) Policy {
+	merged := p.Default
+	if override.RequestsPerSecond > 0 {
+		merged.RequestsPerSecond = override.RequestsPerSecond
+	}
+	if override.MaxConcurrent > 0 {
+		merged.MaxConcurrent = override.MaxConcurrent
+	}
+	if override.MinDelay > 0 {
+		merged.MinDelay = override.MinDelay
+	}
+	return merged
+}
+
+// Limiter enforces Policies per host. Acquire blocks until a request to host
+// may start; the returned release func must be called once the response body
+// has been read.
+type Limiter interface {
+	Acquire(ctx context.Context, host string, crawlDelay time.Duration) (func(), error)
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package politeness
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// leaseTTL must outlive the fetch timeout of the worker.
+	leaseTTL     = time.Minute
+	pollInterval = 250 * time.Millisecond
+)
+
+// PostgresLimiter coordinates per-host limits across every worker process
+// through the host_throttles token table and host_leases.
+type PostgresLimiter struct {
+	DB       *gorm.DB
+	policies Policies
+}
+
+func NewPostgresLimiter(db *gorm.DB, policies Policies) *PostgresLimiter {
+	return &PostgresLimiter{
+		DB:       db,
+		policies: policies,
+	}
+}
+
+func (l *PostgresLimiter) Acquire(ctx context.Context, host string, crawlDelay time.Duration) (func(), error) {
+	host = strings.ToLower(host)
+	policy := l.policies.For(host)
+
+	for {
+		leaseId, wait, err := l.tryAcquire(ctx, host, policy, crawlDelay)
+		if err != nil {
+			return nil, err
+		}
+
+		if leaseId != "" {
+			var once sync.Once
+			release := func() {
+				once.Do(func() { l.release(leaseId) })
+			}
+
+			if err := sleepUntil(ctx, time.Now().Add(wait)); err != nil {
+				release()
+				return nil, err
+			}
+			return release, nil
+		}
+
+		// Semua slot koneksi host sedang dipakai worker lain
+		if err := sleepUntil(ctx, time.Now().Add(pollInterval)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryAcquire reserves the next request slot of host under a row lock and
+// returns how long to wait for it. Times come from the database clock so
+// workers with skewed clocks still agree. The lease id is empty when the host
+// is at its concurrency limit.
+func (l *PostgresLimiter) tryAcquire(ctx context.Context, host string, policy Policy, crawlDelay time.Duration) (string, time.Duration, error) {
+	var leaseId string
+	var wait time.Duration
+
+	err := l.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var now time.Time
+		if err := tx.Raw("SELECT now()").Scan(&now).Error; err != nil {
+			return err
+		}
+
+		throttle := models.HostThrottle{Host: host, NextRequestAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&throttle).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("host = ?", host).
+			First(&throttle).Error; err != nil {
+			return err
+		}
+
+		// Lease dari worker yang mati tanpa release dibersihkan di sini, selagi
+		// baris throttle host masih terkunci
+		if err := tx.Where("host = ? AND expires_at <= ?", host, now).
+			Delete(&models.HostLease{}).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&models.HostLease{}).
+			Where("host = ? AND expires_at > ?", host, now).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active >= int64(policy.maxConcurrent()) {
+			return nil
+		}
+
+		start := now
+		if throttle.NextRequestAt.After(start) {
+			start = throttle.NextRequestAt
+		}
+
+		if err := tx.Model(&throttle).
+			Update("next_request_at", start.Add(policy.Interval(crawlDelay))).Error; err != nil {
+			return err
+		}
+
+		lease := models.HostLease{
+			ID:        uuid.New().String(),
+			Host:      host,
+			ExpiresAt: start.Add(leaseTTL),
+		}
+		if err := tx.Create(&lease).Error; err != nil {
+			return err
+		}
+
+		leaseId = lease.ID
+		wait = start.Sub(now)
+		return nil
+	})
+
+	return leaseId, wait, err
+}
+
+func (l *PostgresLimiter) release(leaseId string) {
+	if err := l.DB.Where("id = ?", leaseId).Delete(&models.HostLease{}).Error; err != nil {
+		log.Printf("[POLITENESS] failed to release lease %s: %v", leaseId, err)
+	}
+}
@@ -0,0 +1,21 @@
+package queue
+
+import "fmt"
+
+const DeadLetterTopic = "crawler-dead-letter"
+
+// RetryTopic is the delay topic for the given attempt. Each attempt has its
+// own topic so every message in it waits roughly the same backoff, and a
+// consumer blocking on the head message never holds back an earlier one.
+func RetryTopic(base string, attempt int) string {
+	return fmt.Sprintf("%s-retry-%d", base, attempt)
+}
+
+// RetryTopics lists the delay topics a worker must consume besides base.
+func RetryTopics(base string, maxAttempts int) []string {
+	topics := make([]string, 0, maxAttempts)
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		topics = append(topics, RetryTopic(base, attempt))
+	}
+	return topics
+}
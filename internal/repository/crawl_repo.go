@@ -7,6 +7,9 @@ import (
 
 type CrawlRepository interface {
 	SavePage(page *models.CrawlPage) error
+	FindPageByID(id string) (*models.CrawlPage, error)
+	FindDescendantPages(id string, limit, offset int) ([]models.CrawlPage, int64, error)
+	FindLatestPage(url, extractHash, excludeId string) (*models.CrawlPage, error)
 }
 
 type CrawlRepositoryImpl struct {
@@ -22,3 +25,51 @@ func NewCrawlRepositoryImpl(db *gorm.DB) *CrawlRepositoryImpl {
 func (r *CrawlRepositoryImpl) SavePage(page *models.CrawlPage) error {
 	return r.DB.Save(page).Error
 }
+
+func (r *CrawlRepositoryImpl) FindPageByID(id string) (*models.CrawlPage, error) {
+	var page models.CrawlPage
+	if err := r.DB.Where("id = ?", id).First(&page).Error; err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// descendantsCTE walks parent_id down from the given page so every page
+// produced by a recursive crawl is reachable from its root.
+const descendantsCTE = `WITH RECURSIVE descendants AS (
+	SELECT * FROM crawl_pages WHERE parent_id = ?
+	UNION ALL
+	SELECT p.* FROM crawl_pages p JOIN descendants d ON p.parent_id = d.id
+)`
+
+func (r *CrawlRepositoryImpl) FindDescendantPages(id string, limit, offset int) ([]models.CrawlPage, int64, error) {
+	var total int64
+	if err := r.DB.Raw(descendantsCTE+" SELECT COUNT(*) FROM descendants", id).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var pages []models.CrawlPage
+	err := r.DB.Raw(descendantsCTE+" SELECT * FROM descendants ORDER BY depth_level DESC, created_at ASC LIMIT ? OFFSET ?", id, limit, offset).
+		Scan(&pages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pages, total, nil
+}
+
+// FindLatestPage returns the most recent page with content fetched from url,
+// in any session, that was extracted with the same options. Pages that only
+// declare url as their canonical are not matched, their content is different.
+// excludeId keeps a redelivered job from matching its own page.
+func (r *CrawlRepositoryImpl) FindLatestPage(url, extractHash, excludeId string) (*models.CrawlPage, error) {
+	var page models.CrawlPage
+	err := r.DB.Where("(final_url = ? OR url = ?) AND extract_hash = ?", url, url, extractHash).
+		Where("status IN ? AND content_hash <> '' AND id <> ?", []string{models.JobStatusSucceeded, models.PageStatusUnchanged}, excludeId).
+		Order("created_at DESC").
+		First(&page).Error
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
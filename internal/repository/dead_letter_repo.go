@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+)
+
+type DeadLetterRepository interface {
+	SaveDeadLetter(deadLetter *models.DeadLetter) error
+	FindDeadLetterByID(id string) (*models.DeadLetter, error)
+	FindDeadLetters(includeReplayed bool, limit, offset int) ([]models.DeadLetter, int64, error)
+	MarkReplayed(id string) error
+}
+
+type DeadLetterRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewDeadLetterRepositoryImpl(db *gorm.DB) *DeadLetterRepositoryImpl {
+	return &DeadLetterRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *DeadLetterRepositoryImpl) SaveDeadLetter(deadLetter *models.DeadLetter) error {
+	return r.DB.Create(deadLetter).Error
+}
+
+func (r *DeadLetterRepositoryImpl) FindDeadLetterByID(id string) (*models.DeadLetter, error) {
+	var deadLetter models.DeadLetter
+	if err := r.DB.Where("id = ?", id).First(&deadLetter).Error; err != nil {
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+func (r *DeadLetterRepositoryImpl) FindDeadLetters(includeReplayed bool, limit, offset int) ([]models.DeadLetter, int64, error) {
+	query := r.DB.Model(&models.DeadLetter{})
+	if !includeReplayed {
+		query = query.Where("replayed_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deadLetters []models.DeadLetter
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deadLetters).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deadLetters, total, nil
+}
+
+func (r *DeadLetterRepositoryImpl) MarkReplayed(id string) error {
+	return r.DB.Model(&models.DeadLetter{}).
+		Where("id = ?", id).
+		Update("replayed_at", time.Now()).Error
+}
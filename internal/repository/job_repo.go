@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidTransition = errors.New("invalid job status transition")
+
+type JobTransition struct {
+	FailureReason   string
+	HTTPStatus      int
+	BytesDownloaded int64
+	Attempts        int
+}
+
+type JobRepository interface {
+	CreateJob(job *models.CrawlJobRecord) error
+	FindJobByID(id string) (*models.CrawlJobRecord, error)
+	TransitionJob(id, status string, transition JobTransition) error
+}
+
+type JobRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewJobRepositoryImpl(db *gorm.DB) *JobRepositoryImpl {
+	return &JobRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *JobRepositoryImpl) CreateJob(job *models.CrawlJobRecord) error {
+	now := time.Now()
+	if job.Status == "" {
+		job.Status = models.JobStatusQueued
+	}
+	if job.Status == models.JobStatusQueued {
+		job.QueuedAt = &now
+	}
+	return r.DB.Create(job).Error
+}
+
+func (r *JobRepositoryImpl) FindJobByID(id string) (*models.CrawlJobRecord, error) {
+	var job models.CrawlJobRecord
+	if err := r.DB.Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// TransitionJob moves a job to status only if its current status allows it,
+// so concurrent workers can't push a finished job back into flight.
+func (r *JobRepositoryImpl) TransitionJob(id, status string, transition JobTransition) error {
+	sources := models.JobTransitionSources(status)
+	if len(sources) == 0 {
+		return fmt.Errorf("%w: unknown status %s", ErrInvalidTransition, status)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": now,
+	}
+	if column := models.JobStatusTimestampColumn(status); column != "" {
+		updates[column] = now
+	}
+	if transition.FailureReason != "" {
+		updates["failure_reason"] = transition.FailureReason
+	}
+	if transition.HTTPStatus != 0 {
+		updates["http_status"] = transition.HTTPStatus
+	}
+	if transition.BytesDownloaded != 0 {
+		updates["bytes_downloaded"] = transition.BytesDownloaded
+	}
+	if transition.Attempts != 0 {
+		updates["attempts"] = transition.Attempts
+	}
+
+	result := r.DB.Model(&models.CrawlJobRecord{}).
+		Where("id = ? AND status IN ?", id, sources).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: job %s to %s", ErrInvalidTransition, id, status)
+	}
+	return nil
+}
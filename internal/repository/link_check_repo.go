@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LinkCheckRepository interface {
+	SaveLinkCheck(check *models.LinkCheck) error
+	FindBrokenLinks(sessionId string, limit, offset int) ([]models.LinkCheck, int64, error)
+}
+
+type LinkCheckRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewLinkCheckRepositoryImpl(db *gorm.DB) *LinkCheckRepositoryImpl {
+	return &LinkCheckRepositoryImpl{
+		DB: db,
+	}
+}
+
+// SaveLinkCheck overwrites an earlier result of the same URL, a redelivered
+// check job keeps only its latest probe.
+func (r *LinkCheckRepositoryImpl) SaveLinkCheck(check *models.LinkCheck) error {
+	return r.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(check).Error
+}
+
+func (r *LinkCheckRepositoryImpl) FindBrokenLinks(sessionId string, limit, offset int) ([]models.LinkCheck, int64, error) {
+	query := r.DB.Model(&models.LinkCheck{}).Where("session_id = ? AND broken = ?", sessionId, true)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var checks []models.LinkCheck
+	if err := query.Order("url ASC").Limit(limit).Offset(offset).Find(&checks).Error; err != nil {
+		return nil, 0, err
+	}
+	return checks, total, nil
+}
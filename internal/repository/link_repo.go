@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+)
+
+// linkBatchSize menjaga jumlah parameter insert di bawah batas Postgres
+const linkBatchSize = 500
+
+type LinkRepository interface {
+	ReplaceLinks(sourcePageId string, links []models.PageLink) error
+	FindOutlinks(sourcePageId string, limit, offset int) ([]models.PageLink, int64, error)
+	FindAllOutlinks(sourcePageId string) ([]models.PageLink, error)
+	FindInlinks(sessionId string, targetUrls []string, limit, offset int) ([]models.PageLink, int64, error)
+	FindReferrers(sessionId string, targetUrls []string) ([]models.PageLink, error)
+}
+
+type LinkRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewLinkRepositoryImpl(db *gorm.DB) *LinkRepositoryImpl {
+	return &LinkRepositoryImpl{
+		DB: db,
+	}
+}
+
+// ReplaceLinks swaps every outlink of the page in one transaction, so a page
+// crawled again (or a redelivered job) never leaves duplicate edges behind.
+func (r *LinkRepositoryImpl) ReplaceLinks(sourcePageId string, links []models.PageLink) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_page_id = ?", sourcePageId).Delete(&models.PageLink{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(links, linkBatchSize).Error
+	})
+}
+
+func (r *LinkRepositoryImpl) FindOutlinks(sourcePageId string, limit, offset int) ([]models.PageLink, int64, error) {
+	query := r.DB.Model(&models.PageLink{}).Where("source_page_id = ?", sourcePageId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var links []models.PageLink
+	if err := query.Order("position ASC").Limit(limit).Offset(offset).Find(&links).Error; err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+func (r *LinkRepositoryImpl) FindAllOutlinks(sourcePageId string) ([]models.PageLink, error) {
+	var links []models.PageLink
+	err := r.DB.Where("source_page_id = ?", sourcePageId).Order("position ASC").Find(&links).Error
+	return links, err
+}
+
+// FindInlinks takes several target URLs because a page can be linked by its
+// fetched URL as well as the canonical URL it declares.
+func (r *LinkRepositoryImpl) FindInlinks(sessionId string, targetUrls []string, limit, offset int) ([]models.PageLink, int64, error) {
+	query := r.DB.Model(&models.PageLink{}).Where("session_id = ? AND target_url IN ?", sessionId, targetUrls)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var links []models.PageLink
+	if err := query.Order("source_page_id ASC, position ASC").Limit(limit).Offset(offset).Find(&links).Error; err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+// FindReferrers returns every edge pointing at one of targetUrls, used to group
+// link check results by the pages that reference them.
+func (r *LinkRepositoryImpl) FindReferrers(sessionId string, targetUrls []string) ([]models.PageLink, error) {
+	var links []models.PageLink
+	if len(targetUrls) == 0 {
+		return links, nil
+	}
+	err := r.DB.Where("session_id = ? AND target_url IN ?", sessionId, targetUrls).
+		Order("target_url ASC, source_page_id ASC, position ASC").
+		Find(&links).Error
+	return links, err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VersionBuilder receives the latest version of a page (nil for a new page)
+// and returns the version to add, or nil when the content did not change.
+type VersionBuilder func(previous *models.PageVersion) *models.PageVersion
+
+type PageVersionRepository interface {
+	RecordCrawl(page *models.CrawlPage, build VersionBuilder) (*models.PageIdentity, *models.PageVersion, error)
+	FindPageIdentityByID(id string) (*models.PageIdentity, error)
+	FindVersions(pageIdentityId string, limit, offset int) ([]models.PageVersion, int64, error)
+	FindVersion(pageIdentityId string, version int) (*models.PageVersion, error)
+}
+
+type PageVersionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewPageVersionRepositoryImpl(db *gorm.DB) *PageVersionRepositoryImpl {
+	return &PageVersionRepositoryImpl{
+		DB: db,
+	}
+}
+
+// RecordCrawl registers page as a crawl of its canonical URL and extraction
+// options (page.ExtractHash) and saves it in the same transaction, so a
+// version or last_crawl_page_id never points at a page that was not written.
+// The identity row is locked while the builder compares against the latest
+// version, so two workers crawling the same URL never create the same version
+// number twice. It returns the identity and the
+// version the crawl ended up on, both are also set on page.
+func (r *PageVersionRepositoryImpl) RecordCrawl(page *models.CrawlPage, build VersionBuilder) (*models.PageIdentity, *models.PageVersion, error) {
+	var identity models.PageIdentity
+	var current *models.PageVersion
+	canonicalUrl := page.CanonicalURL
+	extractHash := page.ExtractHash
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		identity = models.PageIdentity{
+			ID:            uuid.New().String(),
+			CanonicalURL:  canonicalUrl,
+			ExtractHash:   extractHash,
+			FirstSeenAt:   now,
+			LastCrawledAt: now,
+			LastChangedAt: now,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&identity).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("canonical_url = ? AND extract_hash = ?", canonicalUrl, extractHash).
+			First(&identity).Error; err != nil {
+			return err
+		}
+
+		var previous *models.PageVersion
+		if identity.LatestVersion > 0 {
+			var latest models.PageVersion
+			if err := tx.Where("page_identity_id = ? AND version = ?", identity.ID, identity.LatestVersion).
+				First(&latest).Error; err != nil {
+				return err
+			}
+			previous = &latest
+		}
+
+		updates := map[string]interface{}{
+			"last_crawl_page_id": page.ID,
+			"last_crawled_at":    now,
+		}
+
+		version := build(previous)
+		if version == nil {
+			current = previous
+		} else {
+			version.PageIdentityID = identity.ID
+			version.Version = identity.LatestVersion + 1
+			version.CreatedAt = now
+			if err := tx.Create(version).Error; err != nil {
+				return err
+			}
+			current = version
+			updates["latest_version"] = version.Version
+			updates["last_changed_at"] = now
+		}
+
+		page.PageIdentityID = &identity.ID
+		if current != nil {
+			page.PageVersion = current.Version
+		}
+		if err := tx.Save(page).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&identity).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &identity, current, nil
+}
+
+func (r *PageVersionRepositoryImpl) FindPageIdentityByID(id string) (*models.PageIdentity, error) {
+	var identity models.PageIdentity
+	if err := r.DB.Where("id = ?", id).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// FindVersions lists the versions newest first without their full text, the
+// text of one version is read through FindVersion.
+func (r *PageVersionRepositoryImpl) FindVersions(pageIdentityId string, limit, offset int) ([]models.PageVersion, int64, error) {
+	query := r.DB.Model(&models.PageVersion{}).Where("page_identity_id = ?", pageIdentityId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var versions []models.PageVersion
+	err := query.Omit("text").Order("version DESC").Limit(limit).Offset(offset).Find(&versions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return versions, total, nil
+}
+
+func (r *PageVersionRepositoryImpl) FindVersion(pageIdentityId string, version int) (*models.PageVersion, error) {
+	var pageVersion models.PageVersion
+	if err := r.DB.Where("page_identity_id = ? AND version = ?", pageIdentityId, version).First(&pageVersion).Error; err != nil {
+		return nil, err
+	}
+	return &pageVersion, nil
+}
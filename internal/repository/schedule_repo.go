@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduleRepository interface {
+	CreateSchedule(schedule *models.CrawlSchedule) error
+	FindScheduleByID(id string) (*models.CrawlSchedule, error)
+	FindSchedules(limit, offset int) ([]models.CrawlSchedule, int64, error)
+	DeleteSchedule(id string) error
+	SetScheduleActive(id string, active bool, nextRunAt *time.Time) error
+	ClaimDueSchedules(limit int, next func(models.CrawlSchedule) time.Time) ([]models.CrawlSchedule, error)
+	RecordScheduleRun(id, sessionId, status, runErr string) error
+}
+
+type ScheduleRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewScheduleRepositoryImpl(db *gorm.DB) *ScheduleRepositoryImpl {
+	return &ScheduleRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *ScheduleRepositoryImpl) CreateSchedule(schedule *models.CrawlSchedule) error {
+	return r.DB.Create(schedule).Error
+}
+
+func (r *ScheduleRepositoryImpl) FindScheduleByID(id string) (*models.CrawlSchedule, error) {
+	var schedule models.CrawlSchedule
+	if err := r.DB.Where("id = ?", id).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *ScheduleRepositoryImpl) FindSchedules(limit, offset int) ([]models.CrawlSchedule, int64, error) {
+	query := r.DB.Model(&models.CrawlSchedule{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var schedules []models.CrawlSchedule
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&schedules).Error; err != nil {
+		return nil, 0, err
+	}
+	return schedules, total, nil
+}
+
+// DeleteSchedule removes the schedule, sessions it already started are kept.
+func (r *ScheduleRepositoryImpl) DeleteSchedule(id string) error {
+	result := r.DB.Where("id = ?", id).Delete(&models.CrawlSchedule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetScheduleActive pauses or resumes a schedule. nextRunAt replaces the
+// stored next run when it is not nil.
+func (r *ScheduleRepositoryImpl) SetScheduleActive(id string, active bool, nextRunAt *time.Time) error {
+	updates := map[string]interface{}{
+		"active":     active,
+		"updated_at": time.Now(),
+	}
+	if nextRunAt != nil {
+		updates["next_run_at"] = *nextRunAt
+	}
+
+	result := r.DB.Model(&models.CrawlSchedule{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ClaimDueSchedules picks active schedules whose run is due and stores the
+// run after it, computed by next, in the same transaction. Rows are locked
+// with SKIP LOCKED so every replica can poll at once and each run is claimed
+// by exactly one of them. A schedule for which next returns the zero time is
+// deactivated.
+func (r *ScheduleRepositoryImpl) ClaimDueSchedules(limit int, next func(models.CrawlSchedule) time.Time) ([]models.CrawlSchedule, error) {
+	var schedules []models.CrawlSchedule
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("active = ? AND next_run_at <= now()", true).
+			Order("next_run_at ASC").
+			Limit(limit).
+			Find(&schedules).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range schedules {
+			updates := map[string]interface{}{
+				"last_run_at": now,
+				"updated_at":  now,
+			}
+			nextRun := next(schedules[i])
+			if nextRun.IsZero() {
+				updates["active"] = false
+				schedules[i].Active = false
+			} else {
+				updates["next_run_at"] = nextRun
+				schedules[i].NextRunAt = nextRun
+			}
+
+			if err := tx.Model(&models.CrawlSchedule{}).
+				Where("id = ?", schedules[i].ID).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+			schedules[i].LastRunAt = &now
+		}
+		return nil
+	})
+	return schedules, err
+}
+
+// RecordScheduleRun stores the outcome of a claimed run. An empty sessionId
+// keeps the previous session, a skipped run must not hide the session that
+// is still running.
+func (r *ScheduleRepositoryImpl) RecordScheduleRun(id, sessionId, status, runErr string) error {
+	updates := map[string]interface{}{
+		"last_status": status,
+		"last_error":  runErr,
+		"updated_at":  time.Now(),
+	}
+	if sessionId != "" {
+		updates["last_session_id"] = sessionId
+	}
+	return r.DB.Model(&models.CrawlSchedule{}).Where("id = ?", id).Updates(updates).Error
+}
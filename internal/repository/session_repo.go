@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SlotReservation int
+
+const (
+	SlotReserved SlotReservation = iota
+	// SlotReservedPaused counts the job but the session is paused, so it must
+	// be parked instead of published
+	SlotReservedPaused
+	SlotHostLimit
+	SlotBudgetExhausted
+	SlotSessionStopped
+)
+
+var errHostLimit = errors.New("host page limit reached")
+
+type SessionRepository interface {
+	CreateSession(session *models.CrawlSession) error
+	FindSessionByID(id string) (*models.CrawlSession, error)
+	IncrementQueued(id string, count int64) error
+	ReserveSlot(id, host string) (SlotReservation, error)
+	ReserveCheckSlot(id string) (SlotReservation, error)
+	RecordResult(id, jobStatus string, bytes int64) error
+	RequeueJob(id string) error
+	TransitionSession(id, status string) error
+	ParkJob(job *models.ParkedJob) error
+	TakeParkedJobs(sessionId string) ([]models.ParkedJob, error)
+	TakeParkedJob(jobId string) (*models.ParkedJob, error)
+}
+
+type SessionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepositoryImpl(db *gorm.DB) *SessionRepositoryImpl {
+	return &SessionRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *SessionRepositoryImpl) CreateSession(session *models.CrawlSession) error {
+	if session.Status == "" {
+		session.Status = models.SessionStatusRunning
+	}
+	return r.DB.Create(session).Error
+}
+
+func (r *SessionRepositoryImpl) FindSessionByID(id string) (*models.CrawlSession, error) {
+	var session models.CrawlSession
+	if err := r.DB.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepositoryImpl) IncrementQueued(id string, count int64) error {
+	return r.DB.Model(&models.CrawlSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"pages_queued": gorm.Expr("pages_queued + ?", count),
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// RecordResult counts a job that reached a terminal status and completes the
+// session once every queued job has been accounted for. A session stopped
+// earlier gets its finished_at at that point instead.
+func (r *SessionRepositoryImpl) RecordResult(id, jobStatus string, bytes int64) error {
+	var column string
+	switch jobStatus {
+	case models.JobStatusSucceeded:
+		column = "pages_fetched"
+	case models.JobStatusFailed:
+		column = "pages_failed"
+	default:
+		column = "pages_skipped"
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.CrawlSession{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				column:             gorm.Expr(column + " + 1"),
+				"bytes_downloaded": gorm.Expr("bytes_downloaded + ?", bytes),
+				"updated_at":       now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.CrawlSession{}).
+			Where("id = ? AND status = ?", id, models.SessionStatusRunning).
+			Where("pages_queued <= pages_fetched + pages_failed + pages_skipped").
+			Updates(map[string]interface{}{
+				"status":      models.SessionStatusCompleted,
+				"finished_at": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// Session yang dihentikan budget tetap menunggu job yang sudah terkirim sebelum dianggap selesai
+		return tx.Model(&models.CrawlSession{}).
+			Where("id = ? AND status IN ? AND finished_at IS NULL", id, []string{models.SessionStatusBudgetExhausted, models.SessionStatusCancelled}).
+			Where("pages_queued <= pages_fetched + pages_failed + pages_skipped").
+			Update("finished_at", now).Error
+	})
+}
+
+// RequeueJob counts a replayed job as queued again and reopens the session if
+// it had already completed.
+func (r *SessionRepositoryImpl) RequeueJob(id string) error {
+	return r.DB.Model(&models.CrawlSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"pages_queued": gorm.Expr("pages_queued + 1"),
+			"status":       gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", models.SessionStatusCompleted, models.SessionStatusRunning),
+			"finished_at":  gorm.Expr("CASE WHEN status = ? THEN NULL ELSE finished_at END", models.SessionStatusCompleted),
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// ReserveSlot counts one more queued page against the session budget. The
+// conditional updates make the check atomic across workers; when the budget
+// runs out the session is marked budget_exhausted.
+func (r *SessionRepositoryImpl) ReserveSlot(id, host string) (SlotReservation, error) {
+	session, err := r.FindSessionByID(id)
+	if err != nil {
+		return SlotSessionStopped, err
+	}
+	if session.Status != models.SessionStatusRunning && session.Status != models.SessionStatusPaused {
+		return SlotSessionStopped, nil
+	}
+
+	reserved := false
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.CrawlSession{}).
+			Where("id = ? AND status IN ?", id, []string{models.SessionStatusRunning, models.SessionStatusPaused}).
+			Where("max_pages = 0 OR pages_queued - checks_queued < max_pages").
+			Where("max_bytes = 0 OR bytes_downloaded < max_bytes").
+			Where("deadline IS NULL OR deadline > now()").
+			Updates(map[string]interface{}{
+				"pages_queued": gorm.Expr("pages_queued + 1"),
+				"updated_at":   time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if session.MaxPagesPerHost > 0 {
+			result = tx.Exec(`INSERT INTO session_host_counts (session_id, host, pages) VALUES (?, ?, 1)
+				ON CONFLICT (session_id, host) DO UPDATE SET pages = session_host_counts.pages + 1
+				WHERE session_host_counts.pages < ?`, id, host, session.MaxPagesPerHost)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				// Rollback penambahan pages_queued di atas
+				return errHostLimit
+			}
+		}
+
+		reserved = true
+		return nil
+	})
+
+	if errors.Is(err, errHostLimit) {
+		return SlotHostLimit, nil
+	}
+	if err != nil {
+		return SlotSessionStopped, err
+	}
+	if reserved && session.Status == models.SessionStatusPaused {
+		return SlotReservedPaused, nil
+	}
+	if reserved {
+		return SlotReserved, nil
+	}
+
+	// Update tidak kena karena budget habis atau status session baru saja berubah
+	result := r.DB.Model(&models.CrawlSession{}).
+		Where("id = ? AND status = ?", id, models.SessionStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      models.SessionStatusBudgetExhausted,
+			"finished_at": gorm.Expr("CASE WHEN pages_queued <= pages_fetched + pages_failed + pages_skipped THEN now() ELSE NULL END"),
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return SlotBudgetExhausted, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return SlotSessionStopped, nil
+	}
+	return SlotBudgetExhausted, nil
+}
+
+// ReserveCheckSlot counts a link_check probe as queued without charging the
+// page budgets, a probe downloads no page. Probes of pages crawled before the
+// budget ran out are still taken, only a cancelled session or a passed
+// deadline stops them.
+func (r *SessionRepositoryImpl) ReserveCheckSlot(id string) (SlotReservation, error) {
+	session, err := r.FindSessionByID(id)
+	if err != nil {
+		return SlotSessionStopped, err
+	}
+
+	result := r.DB.Model(&models.CrawlSession{}).
+		Where("id = ? AND status IN ?", id, []string{models.SessionStatusRunning, models.SessionStatusPaused, models.SessionStatusBudgetExhausted}).
+		Where("deadline IS NULL OR deadline > now()").
+		Updates(map[string]interface{}{
+			"pages_queued":  gorm.Expr("pages_queued + 1"),
+			"checks_queued": gorm.Expr("checks_queued + 1"),
+			// Session budget_exhausted yang sempat selesai dibuka lagi sampai probe ini tercatat
+			"finished_at": nil,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return SlotSessionStopped, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return SlotSessionStopped, nil
+	}
+	if session.Status == models.SessionStatusPaused {
+		return SlotReservedPaused, nil
+	}
+	return SlotReserved, nil
+}
+
+// TransitionSession moves the session to status if the current status allows it.
+func (r *SessionRepositoryImpl) TransitionSession(id, status string) error {
+	sources := models.SessionTransitionSources(status)
+	if len(sources) == 0 {
+		return fmt.Errorf("%w: unknown session status %s", ErrInvalidTransition, status)
+	}
+
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}
+	if status == models.SessionStatusCancelled {
+		updates["finished_at"] = time.Now()
+	}
+
+	result := r.DB.Model(&models.CrawlSession{}).
+		Where("id = ? AND status IN ?", id, sources).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: session %s to %s", ErrInvalidTransition, id, status)
+	}
+
+	if status == models.SessionStatusRunning {
+		// Semua job bisa saja sudah selesai selama session di-pause
+		return r.DB.Model(&models.CrawlSession{}).
+			Where("id = ? AND status = ?", id, models.SessionStatusRunning).
+			Where("pages_queued <= pages_fetched + pages_failed + pages_skipped").
+			Where("NOT EXISTS (SELECT 1 FROM parked_jobs WHERE parked_jobs.session_id = crawl_sessions.id)").
+			Updates(map[string]interface{}{
+				"status":      models.SessionStatusCompleted,
+				"finished_at": time.Now(),
+			}).Error
+	}
+	return nil
+}
+
+func (r *SessionRepositoryImpl) ParkJob(job *models.ParkedJob) error {
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(job).Error
+}
+
+// TakeParkedJobs deletes and returns every parked job of the session, so two
+// replicas resuming at once never publish the same job twice.
+func (r *SessionRepositoryImpl) TakeParkedJobs(sessionId string) ([]models.ParkedJob, error) {
+	var jobs []models.ParkedJob
+	err := r.DB.Clauses(clause.Returning{}).
+		Where("session_id = ?", sessionId).
+		Delete(&jobs).Error
+	return jobs, err
+}
+
+// TakeParkedJob deletes and returns one parked job, or nil if someone else took it.
+func (r *SessionRepositoryImpl) TakeParkedJob(jobId string) (*models.ParkedJob, error) {
+	var jobs []models.ParkedJob
+	err := r.DB.Clauses(clause.Returning{}).
+		Where("job_id = ?", jobId).
+		Delete(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimLease menahan baris yang sedang diproses satu replica agar tidak diambil replica lain
+const claimLease = 2 * time.Minute
+
+type WatchRepository interface {
+	CreateWatch(watch *models.Watch) error
+	FindWatchByID(id string) (*models.Watch, error)
+	FindWatches(limit, offset int) ([]models.Watch, int64, error)
+	DeleteWatch(id string) error
+	ClaimDueWatches(limit int) ([]models.Watch, error)
+	SetWatchSession(id, sessionId string) error
+	AdvanceWatch(id string, pageIdentityId *string, fromVersion, toVersion int, delivery *models.WebhookDelivery) (bool, error)
+	ClaimDueDeliveries(limit int) ([]models.WebhookDelivery, error)
+	RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error
+	FindDeliveries(watchId string, limit, offset int) ([]models.WebhookDelivery, int64, error)
+}
+
+type WatchRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewWatchRepositoryImpl(db *gorm.DB) *WatchRepositoryImpl {
+	return &WatchRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *WatchRepositoryImpl) CreateWatch(watch *models.Watch) error {
+	return r.DB.Create(watch).Error
+}
+
+func (r *WatchRepositoryImpl) FindWatchByID(id string) (*models.Watch, error) {
+	var watch models.Watch
+	if err := r.DB.Where("id = ?", id).First(&watch).Error; err != nil {
+		return nil, err
+	}
+	return &watch, nil
+}
+
+func (r *WatchRepositoryImpl) FindWatches(limit, offset int) ([]models.Watch, int64, error) {
+	query := r.DB.Model(&models.Watch{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var watches []models.Watch
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&watches).Error; err != nil {
+		return nil, 0, err
+	}
+	return watches, total, nil
+}
+
+// DeleteWatch deactivates the watch, its deliveries stay for auditing.
+func (r *WatchRepositoryImpl) DeleteWatch(id string) error {
+	result := r.DB.Model(&models.Watch{}).
+		Where("id = ? AND active = ?", id, true).
+		Updates(map[string]interface{}{
+			"active":     false,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ClaimDueWatches picks active watches whose run is due and moves their next
+// run forward by the interval in the same transaction. SKIP LOCKED lets every
+// API replica poll at once without running a watch twice.
+func (r *WatchRepositoryImpl) ClaimDueWatches(limit int) ([]models.Watch, error) {
+	var watches []models.Watch
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("active = ? AND next_run_at <= now()", true).
+			Order("next_run_at ASC").
+			Limit(limit).
+			Find(&watches).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range watches {
+			next := now.Add(time.Duration(watches[i].IntervalSeconds) * time.Second)
+			if err := tx.Model(&models.Watch{}).
+				Where("id = ?", watches[i].ID).
+				Updates(map[string]interface{}{
+					"next_run_at": next,
+					"last_run_at": now,
+					"updated_at":  now,
+				}).Error; err != nil {
+				return err
+			}
+			watches[i].NextRunAt = next
+			watches[i].LastRunAt = &now
+		}
+		return nil
+	})
+	return watches, err
+}
+
+func (r *WatchRepositoryImpl) SetWatchSession(id, sessionId string) error {
+	return r.DB.Model(&models.Watch{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_session_id": sessionId,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// AdvanceWatch records that the watch has compared up to toVersion and
+// stores the delivery it fired, if any. It returns false when another worker
+// already advanced the watch from fromVersion.
+func (r *WatchRepositoryImpl) AdvanceWatch(id string, pageIdentityId *string, fromVersion, toVersion int, delivery *models.WebhookDelivery) (bool, error) {
+	advanced := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		updates := map[string]interface{}{
+			"page_identity_id": pageIdentityId,
+			"last_version":     toVersion,
+			"updated_at":       now,
+		}
+		if delivery != nil {
+			updates["last_fired_at"] = now
+		}
+
+		result := tx.Model(&models.Watch{}).
+			Where("id = ? AND last_version = ?", id, fromVersion).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		advanced = true
+
+		if delivery == nil {
+			return nil
+		}
+		return tx.Create(delivery).Error
+	})
+	return advanced, err
+}
+
+// ClaimDueDeliveries picks pending deliveries whose next attempt is due and
+// leases them, so a replica that dies mid-send only delays the retry.
+func (r *WatchRepositoryImpl) ClaimDueDeliveries(limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= now()", models.DeliveryPending).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(claimLease)).Error
+	})
+	return deliveries, err
+}
+
+// RecordAttempt stores the attempt and the delivery state the caller derived
+// from it.
+func (r *WatchRepositoryImpl) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]interface{}{
+				"status":           delivery.Status,
+				"attempts":         delivery.Attempts,
+				"last_status_code": delivery.LastStatusCode,
+				"last_error":       delivery.LastError,
+				"next_attempt_at":  delivery.NextAttemptAt,
+				"delivered_at":     delivery.DeliveredAt,
+				"updated_at":       time.Now(),
+			}).Error
+	})
+}
+
+func (r *WatchRepositoryImpl) FindDeliveries(watchId string, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	query := r.DB.Model(&models.WebhookDelivery{}).Where("watch_id = ?", watchId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
@@ -0,0 +1,166 @@
+package robots
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxRobotsSize is the parsing limit required by RFC 9309 (500 KiB).
+const maxRobotsSize = 500 * 1024
+
+// unreachableTTL is kept short so a host recovering from 5xx is retried soon.
+const unreachableTTL = 5 * time.Minute
+
+// ErrUnreachable is returned by Check when robots.txt answered 5xx or 429 or
+// could not be fetched and no earlier copy is cached. The URL is neither
+// allowed nor disallowed yet, the caller should try again later.
+var ErrUnreachable = errors.New("robots.txt unreachable")
+
+type cacheEntry struct {
+	robots    *Robots
+	expiresAt time.Time
+	reachable bool
+}
+
+// Cache fetches robots.txt once per scheme and host and keeps it for a TTL.
+type Cache struct {
+	Agent     string
+	UserAgent string
+	TTL       time.Duration
+	client    *http.Client
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewCache(agent, userAgent string, ttl time.Duration) *Cache {
+	return &Cache{
+		Agent:     agent,
+		UserAgent: userAgent,
+		TTL:       ttl,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Allowed reports whether the configured agent may fetch rawURL. An
+// unreachable robots.txt counts as disallowed, use Check to tell them apart.
+func (c *Cache) Allowed(rawURL string) bool {
+	allowed, _ := c.Check(rawURL)
+	return allowed
+}
+
+// Check reports whether the configured agent may fetch rawURL. It returns
+// ErrUnreachable instead of false when robots.txt could not be fetched, so
+// only a real Disallow rule comes back as (false, nil).
+func (c *Cache) Check(rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, nil
+	}
+
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	rules := c.Rules(u)
+	if rules.Allowed(c.Agent, path) {
+		return true, nil
+	}
+	if rules.Unreachable() {
+		return false, ErrUnreachable
+	}
+	return false, nil
+}
+
+// CrawlDelay returns the Crawl-delay declared for the host of rawURL.
+func (c *Cache) CrawlDelay(rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	return c.Rules(u).CrawlDelay(c.Agent)
+}
+
+// Rules returns the cached robots.txt for the scheme and host of u, fetching
+// it when missing or expired.
+func (c *Cache) Rules(u *url.URL) *Robots {
+	key := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.robots
+	}
+
+	fetched := c.fetch(key)
+
+	// Kalau server sedang 5xx, pakai salinan terakhir yang berhasil
+	if !fetched.reachable && ok && entry.reachable {
+		fetched.robots = entry.robots
+	}
+
+	c.mu.Lock()
+	c.entries[key] = fetched
+	c.mu.Unlock()
+
+	return fetched.robots
+}
+
+func (c *Cache) fetch(origin string) cacheEntry {
+	robotsUrl := origin + "/robots.txt"
+
+	unreachable := cacheEntry{
+		robots:    DisallowAll(),
+		expiresAt: time.Now().Add(unreachableTTL),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, robotsUrl, nil)
+	if err != nil {
+		log.Printf("[ROBOTS] failed to create request %s: %v", robotsUrl, err)
+		return unreachable
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		log.Printf("[ROBOTS] failed to fetch %s: %v", robotsUrl, err)
+		return unreachable
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		body, err := io.ReadAll(io.LimitReader(res.Body, maxRobotsSize))
+		if err != nil {
+			log.Printf("[ROBOTS] failed to read %s: %v", robotsUrl, err)
+			return unreachable
+		}
+		return cacheEntry{
+			robots:    Parse(body),
+			expiresAt: time.Now().Add(c.TTL),
+			reachable: true,
+		}
+	case res.StatusCode == http.StatusTooManyRequests:
+		// 429 diperlakukan seperti 5xx, bukan izin untuk crawl
+		return unreachable
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		return cacheEntry{
+			robots:    AllowAll(),
+			expiresAt: time.Now().Add(c.TTL),
+			reachable: true,
+		}
+	default:
+		log.Printf("[ROBOTS] unreachable %s with status %d", robotsUrl, res.StatusCode)
+		return unreachable
+	}
+}
@@ -0,0 +1,87 @@
+package robots
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func robotsServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestCacheStatusMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		allowed bool
+		err     error
+	}{
+		{"2xx uses the rules", http.StatusOK, false, nil},
+		{"404 allows all", http.StatusNotFound, true, nil},
+		{"401 allows all", http.StatusUnauthorized, true, nil},
+		{"403 allows all", http.StatusForbidden, true, nil},
+		{"429 is unreachable", http.StatusTooManyRequests, false, ErrUnreachable},
+		{"500 is unreachable", http.StatusInternalServerError, false, ErrUnreachable},
+		{"503 is unreachable", http.StatusServiceUnavailable, false, ErrUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := robotsServer(tt.status, "User-agent: *\nDisallow: /private\n")
+			defer server.Close()
+
+			cache := NewCache("TheCrawler", "TheCrawler/1.0", time.Hour)
+			allowed, err := cache.Check(server.URL + "/private")
+			if allowed != tt.allowed || !errors.Is(err, tt.err) {
+				t.Errorf("Check = %v, %v, want %v, %v", allowed, err, tt.allowed, tt.err)
+			}
+			if cache.Allowed(server.URL+"/private") != tt.allowed {
+				t.Errorf("Allowed disagrees with Check")
+			}
+		})
+	}
+}
+
+func TestCacheNetworkErrorIsUnreachable(t *testing.T) {
+	server := robotsServer(http.StatusOK, "")
+	server.Close()
+
+	cache := NewCache("TheCrawler", "TheCrawler/1.0", time.Hour)
+	if allowed, err := cache.Check(server.URL + "/x"); allowed || !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Check = %v, %v, want false, ErrUnreachable", allowed, err)
+	}
+	// robots.txt sendiri tetap boleh diambil
+	if allowed, err := cache.Check(server.URL + "/robots.txt"); !allowed || err != nil {
+		t.Errorf("Check(/robots.txt) = %v, %v, want true, nil", allowed, err)
+	}
+}
+
+func TestCacheKeepsLastGoodCopyWhileUnreachable(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer server.Close()
+
+	// TTL nol membuat setiap panggilan mengambil robots.txt lagi
+	cache := NewCache("TheCrawler", "TheCrawler/1.0", 0)
+	if allowed, err := cache.Check(server.URL + "/public"); !allowed || err != nil {
+		t.Fatalf("first fetch: %v, %v", allowed, err)
+	}
+
+	status.Store(http.StatusServiceUnavailable)
+	if allowed, err := cache.Check(server.URL + "/public"); !allowed || err != nil {
+		t.Errorf("Check(/public) while down = %v, %v, want the last good copy", allowed, err)
+	}
+	if allowed, err := cache.Check(server.URL + "/private"); allowed || err != nil {
+		t.Errorf("Check(/private) while down = %v, %v, want false, nil", allowed, err)
+	}
+}
@@ -0,0 +1,193 @@
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// Robots is a parsed robots.txt following RFC 9309.
+type Robots struct {
+	groups   []*group
+	Sitemaps []string
+	// disallowAll is set when robots.txt was unreachable (5xx or network error).
+	disallowAll bool
+}
+
+// AllowAll is used when robots.txt is unavailable (4xx).
+func AllowAll() *Robots {
+	return &Robots{}
+}
+
+// DisallowAll is used when robots.txt is unreachable (5xx or network error).
+func DisallowAll() *Robots {
+	return &Robots{disallowAll: true}
+}
+
+// Unreachable reports whether r stands in for a robots.txt that could not be
+// fetched rather than rules the host published.
+func (r *Robots) Unreachable() bool {
+	return r.disallowAll
+}
+
+func Parse(body []byte) *Robots {
+	r := &Robots{}
+
+	var current *group
+	// Baris user-agent berurutan masuk ke group yang sama sampai ada rule
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				current = &group{}
+				r.groups = append(r.groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			lastWasAgent = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		case "sitemap":
+			if value != "" {
+				r.Sitemaps = append(r.Sitemaps, value)
+			}
+		default:
+			lastWasAgent = false
+		}
+	}
+
+	return r
+}
+
+// matchingGroups returns the groups for agent, falling back to "*".
+func (r *Robots) matchingGroups(agent string) []*group {
+	agent = strings.ToLower(agent)
+
+	var matched, wildcard []*group
+	for _, g := range r.groups {
+		for _, a := range g.agents {
+			if a == "*" {
+				wildcard = append(wildcard, g)
+				break
+			}
+			if a == agent {
+				matched = append(matched, g)
+				break
+			}
+		}
+	}
+
+	if len(matched) > 0 {
+		return matched
+	}
+	return wildcard
+}
+
+// Allowed reports whether agent may fetch path (path plus optional query).
+// The longest matching pattern wins and Allow wins a tie.
+func (r *Robots) Allowed(agent, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if r.disallowAll {
+		return false
+	}
+
+	bestLen := -1
+	allowed := true
+	for _, g := range r.matchingGroups(agent) {
+		for _, ru := range g.rules {
+			if !matchPattern(ru.pattern, path) {
+				continue
+			}
+			length := len(ru.pattern)
+			if length > bestLen || (length == bestLen && ru.allow) {
+				bestLen = length
+				allowed = ru.allow
+			}
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the Crawl-delay for agent, or zero if none is declared.
+func (r *Robots) CrawlDelay(agent string) time.Duration {
+	var delay time.Duration
+	for _, g := range r.matchingGroups(agent) {
+		if g.crawlDelay > delay {
+			delay = g.crawlDelay
+		}
+	}
+	return delay
+}
+
+// matchPattern supports "*" for any sequence and a trailing "$" end anchor.
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	if len(parts) == 1 {
+		return !anchored || pos == len(path)
+	}
+
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return true
+}
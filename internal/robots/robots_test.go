@@ -0,0 +1,86 @@
+package robots
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		agent string
+		path  string
+		want  bool
+	}{
+		{"no rules", "", "TheCrawler", "/x", true},
+		{"empty disallow", "User-agent: *\nDisallow:\n", "TheCrawler", "/x", true},
+		{"wildcard group", "User-agent: *\nDisallow: /private\n", "TheCrawler", "/private/a", false},
+		{"prefix only", "User-agent: *\nDisallow: /private\n", "TheCrawler", "/public", true},
+		{"robots.txt always allowed", "User-agent: *\nDisallow: /\n", "TheCrawler", "/robots.txt", true},
+		{"empty path is root", "User-agent: *\nDisallow: /\n", "TheCrawler", "", false},
+		{"comments", "User-agent: * # semua\nDisallow: /a # kecuali b\n", "TheCrawler", "/a", false},
+		{"rule before any group", "Disallow: /a\nUser-agent: *\nAllow: /\n", "TheCrawler", "/a", true},
+
+		// Group untuk agent sendiri menggantikan group *, bukan digabung
+		{"own group wins", "User-agent: *\nDisallow: /private\n\nUser-agent: TheCrawler\nDisallow: /only-us\n", "TheCrawler", "/private", true},
+		{"own group rules", "User-agent: *\nDisallow: /private\n\nUser-agent: TheCrawler\nDisallow: /only-us\n", "TheCrawler", "/only-us", false},
+		{"other agent gets wildcard", "User-agent: *\nDisallow: /private\n\nUser-agent: TheCrawler\nDisallow: /only-us\n", "OtherBot", "/private", false},
+		{"agent case insensitive", "User-agent: thecrawler\nDisallow: /x\n", "TheCrawler", "/x", false},
+		{"consecutive agents share group", "User-agent: a\nUser-agent: TheCrawler\nDisallow: /x\n", "TheCrawler", "/x", false},
+		{"groups of same agent merge", "User-agent: TheCrawler\nDisallow: /x\n\nUser-agent: TheCrawler\nDisallow: /y\n", "TheCrawler", "/y", false},
+
+		{"longer allow wins", "User-agent: *\nDisallow: /a\nAllow: /a/b\n", "TheCrawler", "/a/b/c", true},
+		{"longer disallow wins", "User-agent: *\nAllow: /a\nDisallow: /a/b\n", "TheCrawler", "/a/b/c", false},
+		{"shorter rule still applies", "User-agent: *\nDisallow: /a\nAllow: /a/b\n", "TheCrawler", "/a/c", false},
+		{"tie goes to allow", "User-agent: *\nDisallow: /p\nAllow: /p\n", "TheCrawler", "/p", true},
+
+		{"star matches any sequence", "User-agent: *\nDisallow: /search*q=\n", "TheCrawler", "/search?lang=id&q=go", false},
+		{"star needs the rest", "User-agent: *\nDisallow: /search*q=\n", "TheCrawler", "/search?lang=id", true},
+		{"trailing star", "User-agent: *\nDisallow: /fish*\n", "TheCrawler", "/fish.html", false},
+		{"dollar anchors end", "User-agent: *\nDisallow: /*.pdf$\n", "TheCrawler", "/docs/a.pdf", false},
+		{"dollar rejects suffix", "User-agent: *\nDisallow: /*.pdf$\n", "TheCrawler", "/docs/a.pdf?x=1", true},
+		{"dollar without star", "User-agent: *\nDisallow: /exact$\n", "TheCrawler", "/exact/more", true},
+		{"dollar exact match", "User-agent: *\nDisallow: /exact$\n", "TheCrawler", "/exact", false},
+		{"wildcard beats shorter allow", "User-agent: *\nAllow: /a\nDisallow: /a/*.php\n", "TheCrawler", "/a/b/index.php", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse([]byte(tt.body)).Allowed(tt.agent, tt.path); got != tt.want {
+				t.Errorf("Allowed(%q, %q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlDelayAndSitemaps(t *testing.T) {
+	r := Parse([]byte("Sitemap: https://a.com/one.xml\nUser-agent: *\nCrawl-delay: 2.5\n\nUser-agent: TheCrawler\nCrawl-delay: 1\nCrawl-delay: nope\nSitemap: https://a.com/two.xml\n"))
+
+	if got := r.CrawlDelay("OtherBot"); got != 2500*time.Millisecond {
+		t.Errorf("CrawlDelay(OtherBot) = %s, want 2.5s", got)
+	}
+	if got := r.CrawlDelay("TheCrawler"); got != time.Second {
+		t.Errorf("CrawlDelay(TheCrawler) = %s, want 1s", got)
+	}
+	if got := Parse(nil).CrawlDelay("TheCrawler"); got != 0 {
+		t.Errorf("CrawlDelay without rules = %s, want 0", got)
+	}
+
+	want := []string{"https://a.com/one.xml", "https://a.com/two.xml"}
+	if !reflect.DeepEqual(r.Sitemaps, want) {
+		t.Errorf("Sitemaps = %v, want %v", r.Sitemaps, want)
+	}
+}
+
+func TestAllowAllAndDisallowAll(t *testing.T) {
+	if !AllowAll().Allowed("TheCrawler", "/x") || AllowAll().Unreachable() {
+		t.Error("AllowAll does not allow everything")
+	}
+	if DisallowAll().Allowed("TheCrawler", "/x") || !DisallowAll().Unreachable() {
+		t.Error("DisallowAll does not disallow everything")
+	}
+	if !DisallowAll().Allowed("TheCrawler", "/robots.txt") {
+		t.Error("DisallowAll blocks robots.txt itself")
+	}
+}
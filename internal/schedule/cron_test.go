@@ -0,0 +1,125 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestCronNext(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2026, 1, 1, 10, 0, 30, 0, jakarta), time.Date(2026, 1, 1, 10, 1, 0, 0, jakarta)},
+		{"strictly after", "0 10 * * *", time.Date(2026, 1, 1, 10, 0, 0, 0, jakarta), time.Date(2026, 1, 2, 10, 0, 0, 0, jakarta)},
+		{"location of from", "0 9 * * *", time.Date(2026, 1, 1, 8, 0, 0, 0, jakarta), time.Date(2026, 1, 1, 9, 0, 0, 0, jakarta)},
+		{"month rollover", "0 0 1 * *", time.Date(2026, 12, 15, 0, 0, 0, 0, jakarta), time.Date(2027, 1, 1, 0, 0, 0, 0, jakarta)},
+		{"leap day", "0 0 29 2 *", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2028, 2, 29, 0, 0, 0, 0, jakarta)},
+		{"month names", "0 0 1 jun-aug *", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 6, 1, 0, 0, 0, 0, jakarta)},
+		{"macro", "@weekly", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 4, 0, 0, 0, 0, jakarta)},
+
+		// "5/20" mulai dari 5 sampai akhir range dengan langkah 20
+		{"start step", "5/20 * * * *", time.Date(2026, 1, 1, 10, 0, 0, 0, jakarta), time.Date(2026, 1, 1, 10, 5, 0, 0, jakarta)},
+		{"start step next", "5/20 * * * *", time.Date(2026, 1, 1, 10, 5, 0, 0, jakarta), time.Date(2026, 1, 1, 10, 25, 0, 0, jakarta)},
+		{"start step wraps", "5/20 * * * *", time.Date(2026, 1, 1, 10, 45, 0, 0, jakarta), time.Date(2026, 1, 1, 11, 5, 0, 0, jakarta)},
+		{"star step", "*/15 * * * *", time.Date(2026, 1, 1, 10, 31, 0, 0, jakarta), time.Date(2026, 1, 1, 10, 45, 0, 0, jakarta)},
+		{"range step", "10-30/10 * * * *", time.Date(2026, 1, 1, 10, 21, 0, 0, jakarta), time.Date(2026, 1, 1, 10, 30, 0, 0, jakarta)},
+		{"range step ends", "10-30/10 * * * *", time.Date(2026, 1, 1, 10, 30, 0, 0, jakarta), time.Date(2026, 1, 1, 11, 10, 0, 0, jakarta)},
+
+		// 1 Januari 2026 hari Kamis
+		{"sunday as 0", "0 0 * * 0", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 4, 0, 0, 0, 0, jakarta)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 4, 0, 0, 0, 0, jakarta)},
+		{"range ending at 7", "0 0 * * 6-7", time.Date(2026, 1, 4, 12, 0, 0, 0, jakarta), time.Date(2026, 1, 10, 0, 0, 0, 0, jakarta)},
+		{"sunday name", "0 0 * * SUN", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 4, 0, 0, 0, 0, jakarta)},
+
+		// Day of month dan day of week yang sama-sama dibatasi cukup cocok salah satu
+		{"dom or dow hits dow", "0 0 13 * fri", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 2, 0, 0, 0, 0, jakarta)},
+		{"dom or dow hits dom", "0 0 13 * fri", time.Date(2026, 1, 10, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 13, 0, 0, 0, 0, jakarta)},
+		{"dom only", "0 0 13 * *", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 13, 0, 0, 0, 0, jakarta)},
+		{"dow only", "0 0 * * fri", time.Date(2026, 1, 3, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 9, 0, 0, 0, 0, jakarta)},
+		{"star step dom needs both", "0 0 */2 * mon", time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2026, 1, 5, 0, 0, 0, 0, jakarta)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := c.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	// 8 Maret 2026 jam 02:00 langsung loncat ke 03:00
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"skipped time does not run that day", "30 2 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, newYork), time.Date(2026, 3, 9, 2, 30, 0, 0, newYork)},
+		{"hourly jumps the gap", "0 * * * *", time.Date(2026, 3, 8, 1, 30, 0, 0, newYork), time.Date(2026, 3, 8, 3, 0, 0, 0, newYork)},
+		{"time after the gap", "30 3 * * *", time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), time.Date(2026, 3, 8, 3, 30, 0, 0, newYork)},
+		{"daily keeps wall clock", "0 9 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, newYork), time.Date(2026, 3, 8, 9, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			got := c.Next(tt.from)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+			if got.Location() != newYork {
+				t.Errorf("Next returned location %s", got.Location())
+			}
+		})
+	}
+}
+
+func TestCronNextNoMatch(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if got := c.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next = %s, want zero time", got)
+	}
+}
+
+func TestParseCronRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 0 * *",
+		"0 0 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) accepted", expr)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	// Zona waktu tetap tersedia walaupun image tidak punya tzdata
+	_ "time/tzdata"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+const (
+	// MinInterval mencegah schedule interval membanjiri antrean crawl
+	MinInterval = 60
+	MaxJitter   = 3600
+)
+
+// Validate checks the timing of a schedule submitted through the API and
+// fills in the defaults. Errors are meant to be returned to the caller as is.
+func Validate(s *models.CrawlSchedule) error {
+	switch {
+	case s.Cron == "" && s.IntervalSeconds == 0:
+		return errors.New("either cron or interval_seconds is required")
+	case s.Cron != "" && s.IntervalSeconds != 0:
+		return errors.New("cron and interval_seconds are mutually exclusive")
+	case s.Cron == "" && s.IntervalSeconds < MinInterval:
+		return fmt.Errorf("interval_seconds must be at least %d", MinInterval)
+	}
+
+	if s.TimeZone == "" {
+		s.TimeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return fmt.Errorf("unknown time_zone %q", s.TimeZone)
+	}
+
+	if s.Cron != "" {
+		c, err := ParseCron(s.Cron)
+		if err != nil {
+			return err
+		}
+		if c.Next(time.Now().In(loc)).IsZero() {
+			return fmt.Errorf("cron expression %q never runs", s.Cron)
+		}
+	}
+
+	if s.JitterSeconds < 0 || s.JitterSeconds > MaxJitter {
+		return fmt.Errorf("jitter_seconds must be between 0 and %d", MaxJitter)
+	}
+	if s.IntervalSeconds > 0 && s.JitterSeconds >= s.IntervalSeconds {
+		return errors.New("jitter_seconds must be less than interval_seconds")
+	}
+
+	if s.OverlapPolicy == "" {
+		s.OverlapPolicy = models.OverlapSkip
+	}
+	switch s.OverlapPolicy {
+	case models.OverlapSkip, models.OverlapAllow:
+	default:
+		return fmt.Errorf("unknown overlap_policy %q", s.OverlapPolicy)
+	}
+	return nil
+}
+
+// Next returns the run of s following after, jitter included. Cron
+// expressions are evaluated in the schedule's time zone. The zero time means
+// the schedule has no run left.
+func Next(s models.CrawlSchedule, after time.Time) (time.Time, error) {
+	var next time.Time
+	if s.Cron != "" {
+		c, err := ParseCron(s.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc := time.UTC
+		if s.TimeZone != "" {
+			if loc, err = time.LoadLocation(s.TimeZone); err != nil {
+				return time.Time{}, err
+			}
+		}
+		if next = c.Next(after.In(loc)); next.IsZero() {
+			return next, nil
+		}
+	} else {
+		next = after.Add(time.Duration(s.IntervalSeconds) * time.Second)
+	}
+
+	if s.JitterSeconds > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.JitterSeconds) * int64(time.Second))))
+	}
+	return next, nil
+}
@@ -0,0 +1,268 @@
+package scope
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Matcher decides whether a URL discovered during a crawl is in scope.
+type Matcher struct {
+	mode           string
+	rootHost       string
+	rootDomain     string
+	pathPrefix     string
+	allowedDomains []string
+	include        []*regexp.Regexp
+	exclude        []*regexp.Regexp
+	syntax         string
+	allowedExt     map[string]bool
+	excludedExt    map[string]bool
+	queryPolicy    string
+}
+
+// Compile validates s against the crawl root URL. Errors are meant to be
+// returned to the API caller as is.
+func Compile(rootUrl string, s models.CrawlScope) (*Matcher, error) {
+	root, err := url.Parse(rootUrl)
+	if err != nil || root.Host == "" {
+		return nil, fmt.Errorf("invalid root url %q", rootUrl)
+	}
+
+	m := &Matcher{
+		mode:        s.Mode,
+		rootHost:    strings.ToLower(root.Hostname()),
+		syntax:      s.PatternSyntax,
+		queryPolicy: s.QueryPolicy,
+		allowedExt:  extensionSet(s.AllowedExtensions),
+		excludedExt: extensionSet(s.ExcludedExtensions),
+	}
+
+	if m.mode == "" {
+		m.mode = models.ScopeAny
+	}
+	if m.syntax == "" {
+		m.syntax = models.PatternGlob
+	}
+	if m.queryPolicy == "" {
+		m.queryPolicy = models.QueryKeep
+	}
+
+	switch m.mode {
+	case models.ScopeAny, models.ScopeSameHost:
+	case models.ScopeSameDomain:
+		m.rootDomain = registrableDomain(m.rootHost)
+	case models.ScopeSamePath:
+		m.pathPrefix = s.PathPrefix
+		if m.pathPrefix == "" {
+			m.pathPrefix = defaultPathPrefix(root.EscapedPath())
+		}
+		if !strings.HasPrefix(m.pathPrefix, "/") {
+			return nil, fmt.Errorf("scope path_prefix must start with /")
+		}
+	case models.ScopeAllowlist:
+		if len(s.AllowedDomains) == 0 {
+			return nil, fmt.Errorf("scope allowlist requires allowed_domains")
+		}
+		for _, domain := range s.AllowedDomains {
+			domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+			if domain == "" {
+				return nil, fmt.Errorf("scope allowed_domains must not contain empty values")
+			}
+			m.allowedDomains = append(m.allowedDomains, domain)
+		}
+	default:
+		return nil, fmt.Errorf("unknown scope mode %q", s.Mode)
+	}
+
+	if m.syntax != models.PatternGlob && m.syntax != models.PatternRegex {
+		return nil, fmt.Errorf("unknown pattern_syntax %q", s.PatternSyntax)
+	}
+
+	switch m.queryPolicy {
+	case models.QueryKeep, models.QueryStrip, models.QuerySkip:
+	default:
+		return nil, fmt.Errorf("unknown query_policy %q", s.QueryPolicy)
+	}
+
+	if m.include, err = m.compilePatterns(s.Include); err != nil {
+		return nil, err
+	}
+	if m.exclude, err = m.compilePatterns(s.Exclude); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Matcher) compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expr := pattern
+		if m.syntax == models.PatternGlob {
+			expr = globToRegexp(pattern)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Apply returns the URL to enqueue after the query policy, and whether it is
+// in scope at all.
+func (m *Matcher) Apply(rawUrl string) (string, bool) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", false
+	}
+
+	if u.RawQuery != "" {
+		switch m.queryPolicy {
+		case models.QuerySkip:
+			return "", false
+		case models.QueryStrip:
+			u.RawQuery = ""
+			rawUrl = u.String()
+		}
+	}
+
+	if !m.Allows(u) {
+		return "", false
+	}
+	return rawUrl, true
+}
+
+// Allows checks host scope, patterns and extension filters of u.
+func (m *Matcher) Allows(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+
+	switch m.mode {
+	case models.ScopeSameHost:
+		if host != m.rootHost {
+			return false
+		}
+	case models.ScopeSameDomain:
+		if registrableDomain(host) != m.rootDomain {
+			return false
+		}
+	case models.ScopeSamePath:
+		if host != m.rootHost || !strings.HasPrefix(u.EscapedPath(), m.pathPrefix) {
+			return false
+		}
+	case models.ScopeAllowlist:
+		if !m.inAllowlist(host) {
+			return false
+		}
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")
+	if ext != "" {
+		if len(m.allowedExt) > 0 && !m.allowedExt[ext] {
+			return false
+		}
+		if m.excludedExt[ext] {
+			return false
+		}
+	}
+
+	subject := m.patternSubject(u)
+	if len(m.include) > 0 && !matchAny(m.include, subject) {
+		return false
+	}
+	if matchAny(m.exclude, subject) {
+		return false
+	}
+
+	return true
+}
+
+func (m *Matcher) patternSubject(u *url.URL) string {
+	if m.syntax == models.PatternRegex {
+		return u.String()
+	}
+	subject := u.EscapedPath()
+	if u.RawQuery != "" {
+		subject += "?" + u.RawQuery
+	}
+	return subject
+}
+
+func (m *Matcher) inAllowlist(host string) bool {
+	for _, domain := range m.allowedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []*regexp.Regexp, subject string) bool {
+	for _, re := range patterns {
+		if re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func registrableDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// IP address atau localhost, pakai host apa adanya
+		return host
+	}
+	return domain
+}
+
+func defaultPathPrefix(rootPath string) string {
+	if rootPath == "" {
+		return "/"
+	}
+	if strings.HasSuffix(rootPath, "/") {
+		return rootPath
+	}
+	return rootPath[:strings.LastIndex(rootPath, "/")+1]
+}
+
+func extensionSet(extensions []string) map[string]bool {
+	set := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(ext)), ".")
+		if ext != "" {
+			set[ext] = true
+		}
+	}
+	return set
+}
+
+// globToRegexp anchors the glob: "**" matches anything, "*" anything but "/"
+// and "?" a single character.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
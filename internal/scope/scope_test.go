@@ -0,0 +1,105 @@
+package scope
+
+import (
+	"testing"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		root  string
+		scope models.CrawlScope
+		url   string
+		want  string
+		ok    bool
+	}{
+		{"any mode", "https://a.com/", models.CrawlScope{}, "https://other.org/x", "https://other.org/x", true},
+
+		{"same host", "https://a.com/", models.CrawlScope{Mode: models.ScopeSameHost}, "https://A.com/x", "https://A.com/x", true},
+		{"same host rejects subdomain", "https://a.com/", models.CrawlScope{Mode: models.ScopeSameHost}, "https://www.a.com/x", "", false},
+
+		// Registrable domain diambil dari public suffix list, bukan dua label terakhir
+		{"same domain subdomain", "https://www.a.com/", models.CrawlScope{Mode: models.ScopeSameDomain}, "https://blog.a.com/x", "https://blog.a.com/x", true},
+		{"same domain other domain", "https://www.a.com/", models.CrawlScope{Mode: models.ScopeSameDomain}, "https://a.org/x", "", false},
+		{"same domain multi label suffix", "https://news.bbc.co.uk/", models.CrawlScope{Mode: models.ScopeSameDomain}, "https://www.bbc.co.uk/x", "https://www.bbc.co.uk/x", true},
+		{"same domain sibling under suffix", "https://news.bbc.co.uk/", models.CrawlScope{Mode: models.ScopeSameDomain}, "https://other.co.uk/x", "", false},
+		{"same domain private suffix", "https://alice.github.io/", models.CrawlScope{Mode: models.ScopeSameDomain}, "https://bob.github.io/", "", false},
+		{"same domain ip address", "http://10.0.0.1/", models.CrawlScope{Mode: models.ScopeSameDomain}, "http://10.0.0.1/x", "http://10.0.0.1/x", true},
+
+		{"same path default prefix", "https://a.com/docs/intro", models.CrawlScope{Mode: models.ScopeSamePath}, "https://a.com/docs/api", "https://a.com/docs/api", true},
+		{"same path outside prefix", "https://a.com/docs/intro", models.CrawlScope{Mode: models.ScopeSamePath}, "https://a.com/blog/x", "", false},
+		{"same path other host", "https://a.com/docs/", models.CrawlScope{Mode: models.ScopeSamePath}, "https://b.com/docs/x", "", false},
+		{"same path explicit prefix", "https://a.com/", models.CrawlScope{Mode: models.ScopeSamePath, PathPrefix: "/v2/"}, "https://a.com/v2/x", "https://a.com/v2/x", true},
+
+		{"allowlist domain", "https://a.com/", models.CrawlScope{Mode: models.ScopeAllowlist, AllowedDomains: []string{"B.com"}}, "https://b.com/x", "https://b.com/x", true},
+		{"allowlist subdomain", "https://a.com/", models.CrawlScope{Mode: models.ScopeAllowlist, AllowedDomains: []string{".b.com"}}, "https://cdn.b.com/x", "https://cdn.b.com/x", true},
+		{"allowlist suffix is not a subdomain", "https://a.com/", models.CrawlScope{Mode: models.ScopeAllowlist, AllowedDomains: []string{"b.com"}}, "https://notb.com/x", "", false},
+		{"allowlist excludes root", "https://a.com/", models.CrawlScope{Mode: models.ScopeAllowlist, AllowedDomains: []string{"b.com"}}, "https://a.com/x", "", false},
+
+		{"glob star stays in segment", "https://a.com/", models.CrawlScope{Include: []string{"/blog/*"}}, "https://a.com/blog/post", "https://a.com/blog/post", true},
+		{"glob star does not cross slash", "https://a.com/", models.CrawlScope{Include: []string{"/blog/*"}}, "https://a.com/blog/2024/post", "", false},
+		{"glob double star", "https://a.com/", models.CrawlScope{Include: []string{"/blog/**"}}, "https://a.com/blog/2024/post", "https://a.com/blog/2024/post", true},
+		{"glob is anchored", "https://a.com/", models.CrawlScope{Include: []string{"/blog/*"}}, "https://a.com/old/blog/post", "", false},
+		{"glob question mark", "https://a.com/", models.CrawlScope{Include: []string{"/page?"}}, "https://a.com/page2", "https://a.com/page2", true},
+		{"glob dot is literal", "https://a.com/", models.CrawlScope{Include: []string{"/a.html"}}, "https://a.com/aXhtml", "", false},
+		{"glob sees query", "https://a.com/", models.CrawlScope{Exclude: []string{"/search**"}}, "https://a.com/search?q=go", "", false},
+		{"glob ignores host", "https://a.com/", models.CrawlScope{Include: []string{"a.com/**"}}, "https://a.com/x", "", false},
+
+		{"regex searches full url", "https://a.com/", models.CrawlScope{PatternSyntax: models.PatternRegex, Include: []string{`a\.com/blog/`}}, "https://a.com/blog/2024/post", "https://a.com/blog/2024/post", true},
+		{"regex is unanchored", "https://a.com/", models.CrawlScope{PatternSyntax: models.PatternRegex, Include: []string{`post`}}, "https://a.com/x/post/y", "https://a.com/x/post/y", true},
+		{"regex no match", "https://a.com/", models.CrawlScope{PatternSyntax: models.PatternRegex, Include: []string{`^https://b\.com/`}}, "https://a.com/x", "", false},
+
+		// Exclude selalu menang atas include
+		{"include only", "https://a.com/", models.CrawlScope{Include: []string{"/docs/**"}}, "https://a.com/blog/x", "", false},
+		{"exclude wins over include", "https://a.com/", models.CrawlScope{Include: []string{"/docs/**"}, Exclude: []string{"/docs/private/**"}}, "https://a.com/docs/private/x", "", false},
+		{"include with unrelated exclude", "https://a.com/", models.CrawlScope{Include: []string{"/docs/**"}, Exclude: []string{"/docs/private/**"}}, "https://a.com/docs/public/x", "https://a.com/docs/public/x", true},
+		{"any include matches", "https://a.com/", models.CrawlScope{Include: []string{"/docs/**", "/blog/**"}}, "https://a.com/blog/x", "https://a.com/blog/x", true},
+		{"patterns after host scope", "https://a.com/", models.CrawlScope{Mode: models.ScopeSameHost, Include: []string{"/**"}}, "https://b.com/x", "", false},
+
+		{"allowed extension", "https://a.com/", models.CrawlScope{AllowedExtensions: []string{".HTML"}}, "https://a.com/x.html", "https://a.com/x.html", true},
+		{"not an allowed extension", "https://a.com/", models.CrawlScope{AllowedExtensions: []string{"html"}}, "https://a.com/x.pdf", "", false},
+		{"no extension always passes", "https://a.com/", models.CrawlScope{AllowedExtensions: []string{"html"}}, "https://a.com/x", "https://a.com/x", true},
+		{"excluded extension", "https://a.com/", models.CrawlScope{ExcludedExtensions: []string{"pdf"}}, "https://a.com/x.PDF", "", false},
+
+		{"query kept", "https://a.com/", models.CrawlScope{}, "https://a.com/x?a=1", "https://a.com/x?a=1", true},
+		{"query stripped", "https://a.com/", models.CrawlScope{QueryPolicy: models.QueryStrip}, "https://a.com/x?a=1", "https://a.com/x", true},
+		{"query skipped", "https://a.com/", models.CrawlScope{QueryPolicy: models.QuerySkip}, "https://a.com/x?a=1", "", false},
+		{"skip keeps plain urls", "https://a.com/", models.CrawlScope{QueryPolicy: models.QuerySkip}, "https://a.com/x", "https://a.com/x", true},
+		{"strip before patterns", "https://a.com/", models.CrawlScope{QueryPolicy: models.QueryStrip, Exclude: []string{"/x?*"}}, "https://a.com/x?a=1", "https://a.com/x", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.root, tt.scope)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, ok := m.Apply(tt.url)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Apply(%q) = %q, %v, want %q, %v", tt.url, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompileRejectsInvalidScope(t *testing.T) {
+	tests := map[string]models.CrawlScope{
+		"unknown mode":           {Mode: "everything"},
+		"relative path prefix":   {Mode: models.ScopeSamePath, PathPrefix: "docs/"},
+		"allowlist without list": {Mode: models.ScopeAllowlist},
+		"empty allowed domain":   {Mode: models.ScopeAllowlist, AllowedDomains: []string{" "}},
+		"unknown syntax":         {PatternSyntax: "pcre"},
+		"unknown query policy":   {QueryPolicy: "drop"},
+		"invalid regex":          {PatternSyntax: models.PatternRegex, Include: []string{"("}},
+	}
+	for name, s := range tests {
+		if _, err := Compile("https://a.com/", s); err == nil {
+			t.Errorf("%s: Compile accepted %+v", name, s)
+		}
+	}
+
+	if _, err := Compile("/relative", models.CrawlScope{}); err == nil {
+		t.Error("Compile accepted a relative root url")
+	}
+}
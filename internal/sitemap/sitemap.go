@@ -0,0 +1,304 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Batas dari protokol sitemaps.org: 50MB tanpa kompresi dan 50.000 URL per file
+	maxSitemapBytes = 50 << 20
+	maxIndexDepth   = 3
+	maxSitemaps     = 1000
+	// MaxEntries membatasi jumlah entry yang dikumpulkan Collect, sebesar satu file sitemap penuh
+	MaxEntries = 50000
+	// DefaultPriority dipakai kalau entry tidak menulis priority
+	DefaultPriority = 0.5
+)
+
+// Entry is one <url> of a urlset. Lastmod and Priority are nil when the
+// sitemap left them out.
+type Entry struct {
+	Loc        string
+	Lastmod    *time.Time
+	Priority   *float64
+	ChangeFreq string
+	// Source adalah URL file sitemap tempat entry ini ditemukan
+	Source string
+}
+
+// Acquire blocks until a request to rawUrl may be sent, like politeness.Limiter.
+type Acquire func(ctx context.Context, rawUrl string) (func(), error)
+
+// Fetcher downloads sitemaps, following sitemap indexes and inflating gzip.
+type Fetcher struct {
+	client    *http.Client
+	userAgent string
+	acquire   Acquire
+	allowed   func(rawUrl string) bool
+}
+
+func NewFetcher(userAgent string, acquire Acquire, allowed func(rawUrl string) bool) *Fetcher {
+	return &Fetcher{
+		client:    &http.Client{Timeout: 60 * time.Second},
+		userAgent: userAgent,
+		acquire:   acquire,
+		allowed:   allowed,
+	}
+}
+
+// Candidates returns the sitemaps declared in robots.txt followed by the
+// conventional /sitemap.xml of the root origin, without duplicates.
+func Candidates(rootUrl string, declared []string) []string {
+	seen := make(map[string]bool)
+	candidates := make([]string, 0, len(declared)+1)
+	add := func(raw string) {
+		raw = strings.TrimSpace(raw)
+		if raw != "" && !seen[raw] {
+			seen[raw] = true
+			candidates = append(candidates, raw)
+		}
+	}
+
+	for _, declaredUrl := range declared {
+		add(declaredUrl)
+	}
+	if root, err := url.Parse(rootUrl); err == nil && root.Host != "" {
+		add(root.Scheme + "://" + root.Host + "/sitemap.xml")
+	}
+	return candidates
+}
+
+// Collect fetches every sitemap in sitemapUrls, recursing into indexes, and
+// returns the entries sorted by priority, highest first. Sitemaps that fail
+// are reported in errs and skipped. Entries modified before since are dropped,
+// entries without lastmod are always kept. Walking stops once limit entries
+// are collected, a limit outside 1..MaxEntries means MaxEntries; priorities
+// are only ordered among the entries collected before that.
+func (f *Fetcher) Collect(ctx context.Context, sitemapUrls []string, since *time.Time, limit int) ([]Entry, []error) {
+	if limit <= 0 || limit > MaxEntries {
+		limit = MaxEntries
+	}
+
+	var (
+		entries []Entry
+		errs    []error
+	)
+	fetched := make(map[string]bool)
+
+	var walk func(sitemapUrl string, depth int)
+	walk = func(sitemapUrl string, depth int) {
+		if fetched[sitemapUrl] || len(fetched) >= maxSitemaps || len(entries) >= limit || ctx.Err() != nil {
+			return
+		}
+		fetched[sitemapUrl] = true
+
+		urls, children, err := f.fetch(ctx, sitemapUrl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sitemap %s: %w", sitemapUrl, err))
+			return
+		}
+
+		for _, entry := range urls {
+			if len(entries) >= limit {
+				return
+			}
+			if since != nil && entry.Lastmod != nil && entry.Lastmod.Before(*since) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+
+		if depth >= maxIndexDepth {
+			if len(children) > 0 {
+				errs = append(errs, fmt.Errorf("sitemap %s: index nested deeper than %d levels", sitemapUrl, maxIndexDepth))
+			}
+			return
+		}
+		for _, child := range children {
+			walk(child, depth+1)
+		}
+	}
+	for _, sitemapUrl := range sitemapUrls {
+		walk(sitemapUrl, 0)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return priorityOf(entries[i]) > priorityOf(entries[j])
+	})
+	return entries, errs
+}
+
+// fetch returns the page entries of a urlset or the child sitemaps of an index.
+func (f *Fetcher) fetch(ctx context.Context, sitemapUrl string) ([]Entry, []string, error) {
+	u, err := url.Parse(sitemapUrl)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, nil, fmt.Errorf("invalid url")
+	}
+	if f.allowed != nil && !f.allowed(sitemapUrl) {
+		return nil, nil, fmt.Errorf("disallowed by robots.txt")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "application/xml,text/xml,application/x-gzip,text/plain;q=0.8,*/*;q=0.5")
+
+	if f.acquire != nil {
+		release, err := f.acquire(ctx, sitemapUrl)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer release()
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("status code %d", res.StatusCode)
+	}
+
+	body, err := readBody(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parse(body, res.Request.URL.String())
+}
+
+// readBody inflates gzip by its magic bytes, servers often send .xml.gz with
+// a generic content type or a gzip sitemap without the extension.
+func readBody(r io.Reader) ([]byte, error) {
+	buffered := bufio.NewReader(io.LimitReader(r, maxSitemapBytes+1))
+	magic, _ := buffered.Peek(2)
+
+	var reader io.Reader = buffered
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxSitemapBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSitemapBytes {
+		return nil, fmt.Errorf("sitemap larger than %d bytes", maxSitemapBytes)
+	}
+	return body, nil
+}
+
+type xmlSitemap struct {
+	XMLName  xml.Name
+	URLs     []xmlURL `xml:"url"`
+	Sitemaps []xmlURL `xml:"sitemap"`
+}
+
+type xmlURL struct {
+	Loc        string `xml:"loc"`
+	Lastmod    string `xml:"lastmod"`
+	Priority   string `xml:"priority"`
+	ChangeFreq string `xml:"changefreq"`
+}
+
+func parse(body []byte, source string) ([]Entry, []string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, nil, nil
+	}
+
+	// Sitemap teks: satu URL per baris
+	if trimmed[0] != '<' {
+		var entries []Entry
+		for _, line := range strings.Split(string(trimmed), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+				entries = append(entries, Entry{Loc: line, Source: source})
+			}
+		}
+		return entries, nil, nil
+	}
+
+	var doc xmlSitemap
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	// Encoding selain UTF-8 jarang dipakai di sitemap, isinya dibaca apa adanya
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid sitemap xml: %w", err)
+	}
+
+	switch doc.XMLName.Local {
+	case "sitemapindex":
+		children := make([]string, 0, len(doc.Sitemaps))
+		for _, child := range doc.Sitemaps {
+			if loc := strings.TrimSpace(child.Loc); loc != "" {
+				children = append(children, loc)
+			}
+		}
+		return nil, children, nil
+	case "urlset":
+		entries := make([]Entry, 0, len(doc.URLs))
+		for _, item := range doc.URLs {
+			loc := strings.TrimSpace(item.Loc)
+			if loc == "" {
+				continue
+			}
+			entry := Entry{
+				Loc:        loc,
+				Lastmod:    parseLastmod(item.Lastmod),
+				ChangeFreq: strings.ToLower(strings.TrimSpace(item.ChangeFreq)),
+				Source:     source,
+			}
+			if priority, err := strconv.ParseFloat(strings.TrimSpace(item.Priority), 64); err == nil && priority >= 0 && priority <= 1 {
+				entry.Priority = &priority
+			}
+			entries = append(entries, entry)
+		}
+		return entries, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown sitemap root element %q", doc.XMLName.Local)
+	}
+}
+
+// parseLastmod menerima format W3C Datetime yang dipakai sitemaps.org
+func parseLastmod(raw string) *time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+func priorityOf(entry Entry) float64 {
+	if entry.Priority == nil {
+		return DefaultPriority
+	}
+	return *entry.Priority
+}
@@ -0,0 +1,216 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gzipped(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseUrlset(t *testing.T) {
+	body := "\xef\xbb\xbf" + `<?xml version="1.0" encoding="ISO-8859-1"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc> https://a.com/x </loc>
+    <lastmod>2026-03-01</lastmod>
+    <changefreq>Daily</changefreq>
+    <priority>0.8</priority>
+  </url>
+  <url><loc>https://a.com/y</loc><priority>1.5</priority><lastmod>yesterday</lastmod></url>
+  <url><loc></loc></url>
+</urlset>`
+
+	entries, children, err := parse([]byte(body), "https://a.com/sitemap.xml")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(children) != 0 {
+		t.Errorf("children = %v, want none", children)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", entries)
+	}
+
+	x := entries[0]
+	if x.Loc != "https://a.com/x" || x.ChangeFreq != "daily" || x.Source != "https://a.com/sitemap.xml" {
+		t.Errorf("entry = %+v", x)
+	}
+	if x.Priority == nil || *x.Priority != 0.8 {
+		t.Errorf("priority = %v, want 0.8", x.Priority)
+	}
+	if x.Lastmod == nil || !x.Lastmod.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("lastmod = %v", x.Lastmod)
+	}
+
+	// Priority di luar 0..1 dan lastmod yang tidak dikenal dianggap tidak ada
+	y := entries[1]
+	if y.Priority != nil || y.Lastmod != nil {
+		t.Errorf("entry = %+v, want no priority and lastmod", y)
+	}
+}
+
+func TestParseSitemapIndex(t *testing.T) {
+	body := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://a.com/one.xml</loc><lastmod>2026-01-01</lastmod></sitemap>
+  <sitemap><loc> https://a.com/two.xml.gz </loc></sitemap>
+  <sitemap><loc></loc></sitemap>
+</sitemapindex>`
+
+	entries, children, err := parse([]byte(body), "https://a.com/index.xml")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("entries = %+v, want none", entries)
+	}
+	want := []string{"https://a.com/one.xml", "https://a.com/two.xml.gz"}
+	if !reflect.DeepEqual(children, want) {
+		t.Errorf("children = %v, want %v", children, want)
+	}
+}
+
+func TestParseText(t *testing.T) {
+	body := "https://a.com/x\r\n\n  http://a.com/y  \nftp://a.com/z\nnot a url\n"
+
+	entries, children, err := parse([]byte(body), "https://a.com/sitemap.txt")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(children) != 0 {
+		t.Errorf("children = %v, want none", children)
+	}
+	var locs []string
+	for _, entry := range entries {
+		locs = append(locs, entry.Loc)
+		if entry.Source != "https://a.com/sitemap.txt" {
+			t.Errorf("source = %q", entry.Source)
+		}
+	}
+	if want := []string{"https://a.com/x", "http://a.com/y"}; !reflect.DeepEqual(locs, want) {
+		t.Errorf("locs = %v, want %v", locs, want)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	if entries, children, err := parse([]byte("  \n"), "s"); err != nil || entries != nil || children != nil {
+		t.Errorf("empty body = %v, %v, %v", entries, children, err)
+	}
+	if _, _, err := parse([]byte("<html><body>not found</body></html>"), "s"); err == nil {
+		t.Error("html page accepted as sitemap")
+	}
+	if _, _, err := parse([]byte("<urlset><url>"), "s"); err == nil {
+		t.Error("truncated xml accepted")
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	plain := "<urlset><url><loc>https://a.com/x</loc></url></urlset>"
+
+	got, err := readBody(bytes.NewReader([]byte(plain)))
+	if err != nil || string(got) != plain {
+		t.Errorf("plain body = %q, %v", got, err)
+	}
+
+	// Gzip dikenali dari magic bytes, tanpa melihat ekstensi atau content type
+	got, err = readBody(bytes.NewReader(gzipped(t, plain)))
+	if err != nil || string(got) != plain {
+		t.Errorf("gzip body = %q, %v", got, err)
+	}
+
+	got, err = readBody(bytes.NewReader([]byte{0x1f}))
+	if err != nil || !bytes.Equal(got, []byte{0x1f}) {
+		t.Errorf("one byte body = %q, %v", got, err)
+	}
+
+	if _, err := readBody(bytes.NewReader([]byte{0x1f, 0x8b, 0x00})); err == nil {
+		t.Error("broken gzip accepted")
+	}
+}
+
+func TestParseLastmod(t *testing.T) {
+	jakarta := time.FixedZone("", 7*60*60)
+
+	tests := []struct {
+		raw  string
+		want time.Time
+	}{
+		{"2026-03-01T10:20:30.5+07:00", time.Date(2026, 3, 1, 10, 20, 30, 500000000, jakarta)},
+		{"2026-03-01T10:20:30Z", time.Date(2026, 3, 1, 10, 20, 30, 0, time.UTC)},
+		{"2026-03-01T10:20+07:00", time.Date(2026, 3, 1, 10, 20, 0, 0, jakarta)},
+		{" 2026-03-01 ", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026-03", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := parseLastmod(tt.raw)
+		if got == nil || !got.Equal(tt.want) {
+			t.Errorf("parseLastmod(%q) = %v, want %s", tt.raw, got, tt.want)
+		}
+	}
+
+	for _, raw := range []string{"", "yesterday", "01/03/2026", "2026-13-01"} {
+		if got := parseLastmod(raw); got != nil {
+			t.Errorf("parseLastmod(%q) = %s, want nil", raw, got)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%[1]s/pages.xml.gz</loc></sitemap><sitemap><loc>%[1]s/missing.xml</loc></sitemap><sitemap><loc>%[1]s/index.xml</loc></sitemap></sitemapindex>`, server.URL)
+	})
+	mux.HandleFunc("/pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		// Content type generik seperti yang sering dikirim server untuk .gz
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(gzipped(t, `<urlset>
+  <url><loc>https://a.com/old</loc><lastmod>2025-01-01</lastmod><priority>1.0</priority></url>
+  <url><loc>https://a.com/low</loc><priority>0.1</priority></url>
+  <url><loc>https://a.com/high</loc><lastmod>2026-06-01</lastmod><priority>0.9</priority></url>
+  <url><loc>https://a.com/plain</loc></url>
+</urlset>`))
+	})
+	mux.HandleFunc("/missing.xml", http.NotFound)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fetcher := NewFetcher("TheCrawler/1.0", nil, nil)
+
+	entries, errs := fetcher.Collect(context.Background(), []string{server.URL + "/index.xml"}, &since, 0)
+	var locs []string
+	for _, entry := range entries {
+		locs = append(locs, entry.Loc)
+	}
+	if want := []string{"https://a.com/high", "https://a.com/plain", "https://a.com/low"}; !reflect.DeepEqual(locs, want) {
+		t.Errorf("locs = %v, want %v", locs, want)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "missing.xml") {
+		t.Errorf("errs = %v, want the missing sitemap only", errs)
+	}
+
+	entries, _ = fetcher.Collect(context.Background(), []string{server.URL + "/index.xml"}, nil, 2)
+	if len(entries) != 2 {
+		t.Errorf("limited entries = %+v, want 2", entries)
+	}
+}
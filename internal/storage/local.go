@@ -16,7 +16,11 @@ func NewLocalStorage(basePath string) *LocalStorage {
 }
 
 func (s *LocalStorage) Save(filename string, data []byte) (string, error) {
-	log.Printf("[LOCAL_STORAGE] start to save html raw")
+	return s.SaveAs(filename, "html", data)
+}
+
+func (s *LocalStorage) SaveAs(filename, extension string, data []byte) (string, error) {
+	log.Printf("[LOCAL_STORAGE] start to save %s file", extension)
 	dirStorage := s.BasePath
 	err := os.MkdirAll(dirStorage, 0755)
 	if err != nil {
@@ -24,7 +28,7 @@ func (s *LocalStorage) Save(filename string, data []byte) (string, error) {
 		return "", err
 	}
 
-	fullPath := filepath.Join(dirStorage, fmt.Sprintf("%s.html", filename))
+	fullPath := filepath.Join(dirStorage, fmt.Sprintf("%s.%s", filename, extension))
 
 	err = os.WriteFile(fullPath, data, 0644)
 	if err != nil {
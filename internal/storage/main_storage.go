@@ -2,4 +2,6 @@ package storage
 
 type Storage interface {
 	Save(filename string, data []byte) (string, error)
+	// SaveAs menyimpan file dengan ekstensi lain, mis. md atau txt hasil konversi
+	SaveAs(filename, extension string, data []byte) (string, error)
 }
@@ -0,0 +1,186 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/MrBista/The-Crawler/internal/diff"
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+// MinInterval mencegah watch membebani situs target
+const MinInterval = 60
+
+// Validate checks a watch submitted through the API. Errors are meant to be
+// returned to the caller as is.
+func Validate(w *models.Watch) error {
+	if w.IntervalSeconds < MinInterval {
+		return fmt.Errorf("interval_seconds must be at least %d", MinInterval)
+	}
+
+	target, err := url.Parse(w.WebhookURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("webhook_url must be an absolute http or https url")
+	}
+
+	switch w.Condition {
+	case models.WatchAnyChange:
+	case models.WatchFieldChanged:
+		if w.Field == "" {
+			return fmt.Errorf("condition %s requires field", w.Condition)
+		}
+	case models.WatchThreshold:
+		if w.Field == "" || w.Threshold == nil {
+			return fmt.Errorf("condition %s requires field and threshold", w.Condition)
+		}
+		if w.Direction == "" {
+			w.Direction = models.ThresholdCross
+		}
+		switch w.Direction {
+		case models.ThresholdAbove, models.ThresholdBelow, models.ThresholdCross:
+		default:
+			return fmt.Errorf("unknown direction %q", w.Direction)
+		}
+	case models.WatchTextAppeared, models.WatchTextDisappeared:
+		if strings.TrimSpace(w.Text) == "" {
+			return fmt.Errorf("condition %s requires text", w.Condition)
+		}
+	default:
+		return fmt.Errorf("unknown condition %q", w.Condition)
+	}
+	return nil
+}
+
+// Result tells whether a watch fired and with which values. Old and New hold
+// the watched field, the matched text state or the whole ParsedData,
+// depending on the condition.
+type Result struct {
+	Fired   bool
+	Old     interface{}
+	New     interface{}
+	Changes []models.FieldChange
+}
+
+// Evaluate compares the version a watch saw last with the new one.
+func Evaluate(w *models.Watch, old, new *models.PageVersion) Result {
+	changes := diff.Fields(old.ParsedData, new.ParsedData)
+
+	switch w.Condition {
+	case models.WatchAnyChange:
+		return Result{
+			Fired:   old.ContentHash != new.ContentHash,
+			Old:     old.ParsedData,
+			New:     new.ParsedData,
+			Changes: changes,
+		}
+
+	case models.WatchFieldChanged:
+		oldValue, _ := Lookup(old.ParsedData, w.Field)
+		newValue, _ := Lookup(new.ParsedData, w.Field)
+		return Result{
+			Fired:   !sameValue(oldValue, newValue),
+			Old:     oldValue,
+			New:     newValue,
+			Changes: changes,
+		}
+
+	case models.WatchThreshold:
+		oldValue, _ := Lookup(old.ParsedData, w.Field)
+		newValue, _ := Lookup(new.ParsedData, w.Field)
+		result := Result{Old: oldValue, New: newValue, Changes: changes}
+
+		before, okBefore := toNumber(oldValue)
+		after, okAfter := toNumber(newValue)
+		if !okBefore || !okAfter {
+			return result
+		}
+		threshold := *w.Threshold
+		crossedUp := before < threshold && after >= threshold
+		crossedDown := before > threshold && after <= threshold
+		switch w.Direction {
+		case models.ThresholdAbove:
+			result.Fired = crossedUp
+		case models.ThresholdBelow:
+			result.Fired = crossedDown
+		default:
+			result.Fired = crossedUp || crossedDown
+		}
+		return result
+
+	case models.WatchTextAppeared, models.WatchTextDisappeared:
+		before := containsText(w, old)
+		after := containsText(w, new)
+		fired := !before && after
+		if w.Condition == models.WatchTextDisappeared {
+			fired = before && !after
+		}
+		return Result{Fired: fired, Old: before, New: after, Changes: changes}
+	}
+	return Result{}
+}
+
+// Lookup reads a dotted path such as "offer.price" from ParsedData.
+func Lookup(data map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(data)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func containsText(w *models.Watch, version *models.PageVersion) bool {
+	haystack := version.Text
+	if w.Field != "" {
+		value, _ := Lookup(version.ParsedData, w.Field)
+		switch v := value.(type) {
+		case nil:
+			haystack = ""
+		case string:
+			haystack = v
+		default:
+			encoded, _ := json.Marshal(v)
+			haystack = string(encoded)
+		}
+	}
+	return strings.Contains(strings.ToLower(haystack), strings.ToLower(w.Text))
+}
+
+// sameValue compares through JSON so int64 and float64 of the same number match.
+func sameValue(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(encodedA) == string(encodedB)
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,131 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/MrBista/The-Crawler/internal/models"
+)
+
+func version(hash, text string, parsed models.JSONB) *models.PageVersion {
+	return &models.PageVersion{ContentHash: hash, Text: text, ParsedData: parsed}
+}
+
+func TestEvaluateAnyChange(t *testing.T) {
+	w := &models.Watch{Condition: models.WatchAnyChange}
+
+	old := version("a", "", models.JSONB{"title": "Old"})
+	if got := Evaluate(w, old, version("b", "", models.JSONB{"title": "New"})); !got.Fired || len(got.Changes) != 1 {
+		t.Errorf("changed content: %+v", got)
+	}
+	if got := Evaluate(w, old, version("a", "", models.JSONB{"title": "Old"})); got.Fired {
+		t.Errorf("same content fired: %+v", got)
+	}
+}
+
+func TestEvaluateFieldChanged(t *testing.T) {
+	w := &models.Watch{Condition: models.WatchFieldChanged, Field: "offer.price"}
+
+	tests := []struct {
+		name     string
+		old, new models.JSONB
+		fired    bool
+	}{
+		{"changed", models.JSONB{"offer": map[string]interface{}{"price": 100.0}}, models.JSONB{"offer": map[string]interface{}{"price": 90.0}}, true},
+		// int64 hasil ekstraksi dan float64 dari jsonb dianggap sama
+		{"same number", models.JSONB{"offer": map[string]interface{}{"price": 100.0}}, models.JSONB{"offer": map[string]interface{}{"price": int64(100)}}, false},
+		{"other field changed", models.JSONB{"title": "a", "offer": map[string]interface{}{"price": 100.0}}, models.JSONB{"title": "b", "offer": map[string]interface{}{"price": 100.0}}, false},
+		{"field appeared", models.JSONB{}, models.JSONB{"offer": map[string]interface{}{"price": 100.0}}, true},
+		{"field removed", models.JSONB{"offer": map[string]interface{}{"price": 100.0}}, models.JSONB{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(w, version("a", "", tt.old), version("b", "", tt.new))
+			if got.Fired != tt.fired {
+				t.Errorf("Fired = %v, want %v (old %v, new %v)", got.Fired, tt.fired, got.Old, got.New)
+			}
+		})
+	}
+}
+
+func TestEvaluateThreshold(t *testing.T) {
+	threshold := 100.0
+
+	tests := []struct {
+		name      string
+		direction string
+		old, new  interface{}
+		fired     bool
+	}{
+		{"above crossed up", models.ThresholdAbove, 90.0, 110.0, true},
+		{"above reaches threshold", models.ThresholdAbove, 90.0, 100.0, true},
+		{"above stays above", models.ThresholdAbove, 110.0, 120.0, false},
+		{"above crossed down", models.ThresholdAbove, 110.0, 90.0, false},
+		{"below crossed down", models.ThresholdBelow, 110.0, 90.0, true},
+		{"below reaches threshold", models.ThresholdBelow, 110.0, 100.0, true},
+		{"below stays below", models.ThresholdBelow, 90.0, 80.0, false},
+		{"below crossed up", models.ThresholdBelow, 90.0, 110.0, false},
+		{"cross up", models.ThresholdCross, 90.0, 110.0, true},
+		{"cross down", models.ThresholdCross, 110.0, 90.0, true},
+		{"cross stays", models.ThresholdCross, 110.0, 120.0, false},
+		{"starts at threshold", models.ThresholdCross, 100.0, 120.0, false},
+		{"string numbers", models.ThresholdAbove, "90", " 110 ", true},
+		{"int64", models.ThresholdAbove, int64(90), int64(110), true},
+		{"missing old value", models.ThresholdAbove, nil, 110.0, false},
+		{"not a number", models.ThresholdAbove, 90.0, "sold out", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &models.Watch{Condition: models.WatchThreshold, Field: "price", Threshold: &threshold, Direction: tt.direction}
+			old, new := models.JSONB{}, models.JSONB{}
+			if tt.old != nil {
+				old["price"] = tt.old
+			}
+			if tt.new != nil {
+				new["price"] = tt.new
+			}
+
+			got := Evaluate(w, version("a", "", old), version("b", "", new))
+			if got.Fired != tt.fired {
+				t.Errorf("Fired = %v, want %v", got.Fired, tt.fired)
+			}
+			if got.Old != old["price"] || got.New != new["price"] {
+				t.Errorf("values = %v -> %v, want %v -> %v", got.Old, got.New, old["price"], new["price"])
+			}
+		})
+	}
+}
+
+func TestEvaluateText(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		field     string
+		old, new  *models.PageVersion
+		fired     bool
+	}{
+		{"appeared", models.WatchTextAppeared, "", version("a", "Coming soon", nil), version("b", "Now IN STOCK", nil), true},
+		{"already there", models.WatchTextAppeared, "", version("a", "in stock", nil), version("b", "still in stock", nil), false},
+		{"appeared then gone", models.WatchTextAppeared, "", version("a", "in stock", nil), version("b", "sold out", nil), false},
+		{"disappeared", models.WatchTextDisappeared, "", version("a", "In stock", nil), version("b", "Sold out", nil), true},
+		{"never there", models.WatchTextDisappeared, "", version("a", "sold out", nil), version("b", "sold out", nil), false},
+		{"appeared in field", models.WatchTextAppeared, "status", version("a", "in stock", models.JSONB{"status": "sold out"}), version("b", "in stock", models.JSONB{"status": "In stock"}), true},
+		{"field missing", models.WatchTextDisappeared, "status", version("a", "", models.JSONB{"status": "in stock"}), version("b", "in stock", models.JSONB{}), true},
+		{"non string field", models.WatchTextAppeared, "tags", version("a", "", models.JSONB{"tags": []interface{}{"new"}}), version("b", "", models.JSONB{"tags": []interface{}{"new", "in stock"}}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &models.Watch{Condition: tt.condition, Field: tt.field, Text: "in stock"}
+			got := Evaluate(w, tt.old, tt.new)
+			if got.Fired != tt.fired {
+				t.Errorf("Fired = %v, want %v (old %v, new %v)", got.Fired, tt.fired, got.Old, got.New)
+			}
+		})
+	}
+}
+
+func TestEvaluateUnknownCondition(t *testing.T) {
+	w := &models.Watch{Condition: "whatever"}
+	if got := Evaluate(w, version("a", "", nil), version("b", "", nil)); got.Fired {
+		t.Errorf("unknown condition fired: %+v", got)
+	}
+}
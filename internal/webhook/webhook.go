@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Crawler-Signature"
+	HeaderTimestamp = "X-Crawler-Timestamp"
+	HeaderDelivery  = "X-Crawler-Delivery"
+	HeaderEvent     = "X-Crawler-Event"
+)
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.body". Receivers recompute
+// it with the watch secret and should reject old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign in constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	expected := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// NewSecret generates a random signing secret for a watch.
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sender posts signed JSON payloads. Client is exposed so callers can use a
+// test server's client.
+type Sender struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+func NewSender(userAgent string) *Sender {
+	return &Sender{
+		Client:    &http.Client{Timeout: 15 * time.Second},
+		UserAgent: userAgent,
+	}
+}
+
+// Result is the outcome of one delivery attempt. Err is set for network
+// errors and non-2xx responses alike.
+type Result struct {
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+func (s *Sender) Send(ctx context.Context, url, secret, deliveryId, event string, payload []byte) Result {
+	started := time.Now()
+	timestamp := started.Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return Result{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.UserAgent)
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(secret, timestamp, payload))
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return Result{Duration: time.Since(started), Err: err}
+	}
+	defer res.Body.Close()
+	// Body dibaca sebagian supaya koneksi bisa dipakai ulang
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	result := Result{StatusCode: res.StatusCode, Duration: time.Since(started)}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		result.Err = fmt.Errorf("receiver answered %d", res.StatusCode)
+	}
+	return result
+}
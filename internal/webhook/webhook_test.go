@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"watch.triggered"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	// HMAC-SHA256 dalam hex selalu 64 karakter
+	if len(signature) != 64 {
+		t.Fatalf("signature %q is not hex sha256", signature)
+	}
+	if signature != Sign("secret", 1700000000, body) {
+		t.Fatal("Sign is not deterministic")
+	}
+	if !Verify("secret", 1700000000, body, signature) {
+		t.Fatal("Verify rejected its own signature")
+	}
+
+	tests := map[string]bool{
+		"other secret":    Verify("other", 1700000000, body, signature),
+		"other timestamp": Verify("secret", 1700000001, body, signature),
+		"other body":      Verify("secret", 1700000000, []byte(`{"event":"x"}`), signature),
+		"empty signature": Verify("secret", 1700000000, body, ""),
+		"prefixed":        Verify("secret", 1700000000, body, "sha256="+signature),
+	}
+	for name, ok := range tests {
+		if ok {
+			t.Errorf("%s: Verify accepted", name)
+		}
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	payload := []byte(`{"watch_id":"w1"}`)
+
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := &Sender{Client: server.Client(), UserAgent: "TheCrawler/1.0"}
+	result := sender.Send(context.Background(), server.URL, "secret", "delivery-1", "watch.triggered", payload)
+
+	if result.Err != nil || result.StatusCode != http.StatusNoContent {
+		t.Fatalf("result = %+v", result)
+	}
+	if received.Method != http.MethodPost {
+		t.Errorf("method = %s", received.Method)
+	}
+	if string(receivedBody) != string(payload) {
+		t.Errorf("body = %s", receivedBody)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   "TheCrawler/1.0",
+		HeaderEvent:    "watch.triggered",
+		HeaderDelivery: "delivery-1",
+	}
+	for name, want := range headers {
+		if got := received.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", HeaderTimestamp, err)
+	}
+	signature, ok := strings.CutPrefix(received.Header.Get(HeaderSignature), "sha256=")
+	if !ok {
+		t.Fatalf("%s = %q, want sha256= prefix", HeaderSignature, received.Header.Get(HeaderSignature))
+	}
+	if !Verify("secret", timestamp, receivedBody, signature) {
+		t.Error("receiver can not verify the signature")
+	}
+}
+
+func TestSendReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	sender := &Sender{Client: server.Client()}
+
+	result := sender.Send(context.Background(), server.URL, "secret", "delivery-1", "watch.triggered", []byte(`{}`))
+	if result.Err == nil || result.StatusCode != http.StatusBadGateway {
+		t.Errorf("non-2xx result = %+v", result)
+	}
+
+	server.Close()
+	result = sender.Send(context.Background(), server.URL, "secret", "delivery-1", "watch.triggered", []byte(`{}`))
+	if result.Err == nil || result.StatusCode != 0 {
+		t.Errorf("network error result = %+v", result)
+	}
+}